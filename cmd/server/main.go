@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rifqi142/indico-be/internal/config"
 	"github.com/rifqi142/indico-be/internal/controllers"
+	"github.com/rifqi142/indico-be/internal/jobs"
+	"github.com/rifqi142/indico-be/internal/models"
 	"github.com/rifqi142/indico-be/internal/repository"
 	"github.com/rifqi142/indico-be/internal/routes"
 	"github.com/rifqi142/indico-be/internal/seeders"
@@ -42,16 +46,82 @@ func main() {
 		log.Fatalf("Invalid JWT expiration format: %v", err)
 	}
 
+	// Parse idempotency key TTL
+	idempotencyKeyTTL, err := time.ParseDuration(cfg.IdempotencyKeyTTL)
+	if err != nil {
+		log.Fatalf("Invalid idempotency key TTL format: %v", err)
+	}
+	idempotencyPurgeInterval, err := time.ParseDuration(cfg.IdempotencyPurgeInterval)
+	if err != nil {
+		log.Fatalf("Invalid idempotency purge interval format: %v", err)
+	}
+
+	// Parse reservation settings
+	reservationTTL, err := time.ParseDuration(cfg.ReservationTTL)
+	if err != nil {
+		log.Fatalf("Invalid reservation TTL format: %v", err)
+	}
+	reservationSweepInterval, err := time.ParseDuration(cfg.ReservationSweepInterval)
+	if err != nil {
+		log.Fatalf("Invalid reservation sweep interval format: %v", err)
+	}
+
+	// Parse approval thresholds
+	approvalDiscountPercent, err := models.ParseDecimal(cfg.ApprovalDiscountPercent)
+	if err != nil {
+		log.Fatalf("Invalid approval discount percent: %v", err)
+	}
+	approvalMaxUsage, err := strconv.Atoi(cfg.ApprovalMaxUsage)
+	if err != nil {
+		log.Fatalf("Invalid approval max usage: %v", err)
+	}
+	approvalPolicy := services.ApprovalPolicy{
+		PercentDiscount: approvalDiscountPercent,
+		MaxUsage:        approvalMaxUsage,
+	}
+
+	// Parse trash retention; 0 days keeps deleted vouchers until purged by hand
+	trashRetentionDays, err := strconv.Atoi(cfg.TrashRetentionDays)
+	if err != nil || trashRetentionDays < 0 {
+		log.Fatalf("Invalid trash retention days: %s", cfg.TrashRetentionDays)
+	}
+	trashRetention := time.Duration(trashRetentionDays) * 24 * time.Hour
+	trashPurgeInterval, err := time.ParseDuration(cfg.TrashPurgeInterval)
+	if err != nil {
+		log.Fatalf("Invalid trash purge interval format: %v", err)
+	}
+
 	// Initialize repositories
 	voucherRepo := repository.NewVoucherRepository(db)
+	redemptionRepo := repository.NewRedemptionRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	campaignRepo := repository.NewCampaignRepository(db)
+	changeRequestRepo := repository.NewChangeRequestRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
 
 	// Initialize services
 	authService := services.NewAuthService(cfg.JWTSecret, jwtExpiration)
-	voucherService := services.NewVoucherService(voucherRepo)
+	voucherService := services.NewVoucherService(voucherRepo, campaignRepo, changeRequestRepo, auditRepo, approvalPolicy, trashRetention)
+	redemptionService := services.NewRedemptionService(voucherRepo, redemptionRepo, campaignRepo, auditRepo, reservationTTL)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, idempotencyKeyTTL)
+	campaignService := services.NewCampaignService(campaignRepo, auditRepo)
+	auditService := services.NewAuditService(auditRepo)
+
+	// Start background jobs
+	jobs.StartReservationSweeper(context.Background(), redemptionService, reservationSweepInterval)
+	jobs.StartIdempotencyKeyPurger(context.Background(), idempotencyService, idempotencyPurgeInterval)
+	if trashRetention > 0 {
+		jobs.StartTrashPurger(context.Background(), voucherService, trashPurgeInterval)
+	}
 
 	// Initialize controllers
 	authController := controllers.NewAuthController(authService)
 	voucherController := controllers.NewVoucherController(voucherService)
+	redemptionController := controllers.NewRedemptionController(redemptionService)
+	campaignController := controllers.NewCampaignController(campaignService)
+	changeRequestController := controllers.NewChangeRequestController(voucherService)
+	auditController := controllers.NewAuditController(auditService)
+	trashController := controllers.NewTrashController(voucherService)
 
 	// Setup Gin
 	if cfg.AppEnv == "production" {
@@ -60,7 +130,7 @@ func main() {
 	router := gin.Default()
 
 	// Setup routes
-	routes.SetupRoutes(router, authController, voucherController, cfg.JWTSecret)
+	routes.SetupRoutes(router, authController, voucherController, redemptionController, campaignController, changeRequestController, auditController, trashController, idempotencyService, cfg.JWTSecret)
 
 	// Start server
 	addr := fmt.Sprintf(":%s", cfg.AppPort)
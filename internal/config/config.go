@@ -9,19 +9,27 @@ import (
 )
 
 type Config struct {
-	AppName          string
-	AppEnv           string
-	AppPort          string
-	DBHost           string
-	DBPort           string
-	DBUser           string
-	DBPassword       string
-	DBName           string
-	DBSSLMode        string
-	JWTSecret        string
-	JWTExpiration    string
-	ServerReadTimeout  string
-	ServerWriteTimeout string
+	AppName                  string
+	AppEnv                   string
+	AppPort                  string
+	DBHost                   string
+	DBPort                   string
+	DBUser                   string
+	DBPassword               string
+	DBName                   string
+	DBSSLMode                string
+	JWTSecret                string
+	JWTExpiration            string
+	ServerReadTimeout        string
+	ServerWriteTimeout       string
+	IdempotencyKeyTTL        string
+	IdempotencyPurgeInterval string
+	ReservationTTL           string
+	ReservationSweepInterval string
+	ApprovalDiscountPercent  string
+	ApprovalMaxUsage         string
+	TrashRetentionDays       string
+	TrashPurgeInterval       string
 }
 
 func LoadConfig() *Config {
@@ -31,19 +39,27 @@ func LoadConfig() *Config {
 	}
 
 	config := &Config{
-		AppName:            getEnv("APP_NAME", "indico-be"),
-		AppEnv:             getEnv("APP_ENV", "development"),
-		AppPort:            getEnv("APP_PORT", "8080"),
-		DBHost:             getEnv("DB_HOST", "localhost"),
-		DBPort:             getEnv("DB_PORT", "5432"),
-		DBUser:             getEnv("DB_USER", "postgres"),
-		DBPassword:         getEnv("DB_PASSWORD", "rajawali02"),
-		DBName:             getEnv("DB_NAME", "indico_db"),
-		DBSSLMode:          getEnv("DB_SSL_MODE", "disable"),
-		JWTSecret:          getEnv("JWT_SECRET", "your_secret_key"),
-		JWTExpiration:      getEnv("JWT_EXPIRATION", "24h"),
-		ServerReadTimeout:  getEnv("SERVER_READ_TIMEOUT", "10s"),
-		ServerWriteTimeout: getEnv("SERVER_WRITE_TIMEOUT", "10s"),
+		AppName:                  getEnv("APP_NAME", "indico-be"),
+		AppEnv:                   getEnv("APP_ENV", "development"),
+		AppPort:                  getEnv("APP_PORT", "8080"),
+		DBHost:                   getEnv("DB_HOST", "localhost"),
+		DBPort:                   getEnv("DB_PORT", "5432"),
+		DBUser:                   getEnv("DB_USER", "postgres"),
+		DBPassword:               getEnv("DB_PASSWORD", "rajawali02"),
+		DBName:                   getEnv("DB_NAME", "indico_db"),
+		DBSSLMode:                getEnv("DB_SSL_MODE", "disable"),
+		JWTSecret:                getEnv("JWT_SECRET", "your_secret_key"),
+		JWTExpiration:            getEnv("JWT_EXPIRATION", "24h"),
+		ServerReadTimeout:        getEnv("SERVER_READ_TIMEOUT", "10s"),
+		ServerWriteTimeout:       getEnv("SERVER_WRITE_TIMEOUT", "10s"),
+		IdempotencyKeyTTL:        getEnv("IDEMPOTENCY_KEY_TTL", "24h"),
+		IdempotencyPurgeInterval: getEnv("IDEMPOTENCY_PURGE_INTERVAL", "1h"),
+		ReservationTTL:           getEnv("RESERVATION_TTL", "15m"),
+		ReservationSweepInterval: getEnv("RESERVATION_SWEEP_INTERVAL", "1m"),
+		ApprovalDiscountPercent:  getEnv("APPROVAL_DISCOUNT_PERCENT", "50"),
+		ApprovalMaxUsage:         getEnv("APPROVAL_MAX_USAGE", "10000"),
+		TrashRetentionDays:       getEnv("TRASH_RETENTION_DAYS", "30"),
+		TrashPurgeInterval:       getEnv("TRASH_PURGE_INTERVAL", "1h"),
 	}
 
 	return config
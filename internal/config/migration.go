@@ -1,17 +1,48 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
 
 	"github.com/rifqi142/indico-be/internal/models"
 	"gorm.io/gorm"
 )
 
+// moneyColumns used to be floating point and now hold hundredths
+// (minor units for amounts, hundredths of a percent for percentages).
+var moneyColumns = map[string][]string{
+	"vouchers":            {"discount", "max_discount", "min_order_amount"},
+	"voucher_redemptions": {"order_amount", "discount_amount"},
+}
+
 func RunAutoMigration(db *gorm.DB) error {
 	log.Println("Running auto migration...")
 
+	if err := migrateMoneyColumns(db); err != nil {
+		return err
+	}
+	if err := migrateVoucherStatus(db); err != nil {
+		return err
+	}
+	if err := migrateVoucherCodeIndex(db); err != nil {
+		return err
+	}
+	if err := migrateVoucherCodes(db); err != nil {
+		return err
+	}
+
 	err := db.AutoMigrate(
+		&models.Campaign{},
 		&models.Voucher{},
+		&models.VoucherTarget{},
+		&models.VoucherSchedule{},
+		&models.Redemption{},
+		&models.VoucherChangeRequest{},
+		&models.AuditLog{},
+		&models.IdempotencyKey{},
 	)
 
 	if err != nil {
@@ -21,3 +52,188 @@ func RunAutoMigration(db *gorm.DB) error {
 	log.Println("Auto migration completed successfully")
 	return nil
 }
+
+// migrateMoneyColumns converts the old float columns to BIGINT hundredths,
+// rounding each stored value once, half away from zero. AutoMigrate would
+// otherwise change the type without scaling the data.
+func migrateMoneyColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for table, names := range moneyColumns {
+		if !migrator.HasTable(table) {
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(table)
+		if err != nil {
+			return err
+		}
+
+		for _, columnType := range columnTypes {
+			if !isMoneyColumn(names, columnType.Name()) {
+				continue
+			}
+			switch strings.ToLower(columnType.DatabaseTypeName()) {
+			case "float4", "float8", "real", "double precision", "numeric", "decimal":
+			default:
+				continue
+			}
+
+			log.Printf("Converting %s.%s to minor units...", table, columnType.Name())
+			sql := fmt.Sprintf(`ALTER TABLE %q ALTER COLUMN %q TYPE BIGINT USING ROUND(%q::NUMERIC * 100)::BIGINT`,
+				table, columnType.Name(), columnType.Name())
+			if err := db.Exec(sql).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isMoneyColumn(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateVoucherStatus replaces the old is_active flag with the status
+// column, keeping inactive vouchers paused rather than turning them back on.
+func migrateVoucherStatus(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("vouchers") || !migrator.HasColumn("vouchers", "is_active") {
+		return nil
+	}
+
+	log.Println("Converting vouchers.is_active to status...")
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`ALTER TABLE "vouchers" ADD COLUMN IF NOT EXISTS "status" VARCHAR(20) NOT NULL DEFAULT 'active'`,
+			`UPDATE "vouchers" SET "status" = CASE WHEN "is_active" THEN 'active' ELSE 'paused' END`,
+			`ALTER TABLE "vouchers" DROP COLUMN "is_active"`,
+		}
+		for _, sql := range statements {
+			if err := tx.Exec(sql).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateVoucherCodeIndex drops the old unique index on vouchers.code, which
+// also covered soft-deleted rows. AutoMigrate then creates the partial index
+// over live vouchers only; the old index was stricter, so no live duplicates
+// can stand in its way.
+func migrateVoucherCodeIndex(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable("vouchers") || !migrator.HasIndex("vouchers", "idx_vouchers_code") {
+		return nil
+	}
+
+	log.Println("Replacing vouchers.code unique index with a live-only one...")
+	return migrator.DropIndex("vouchers", "idx_vouchers_code")
+}
+
+// migrateVoucherCodes rewrites stored codes into normalized form before
+// AutoMigrate adds the check constraint that requires it. Live codes that
+// collide once normalized are resolved in favour of the existing voucher: a
+// code already in normalized form keeps it, and among the others the oldest
+// voucher (lowest ID) takes it first. A code that is taken, or too short once
+// normalized, gets the voucher ID appended, e.g. WELCOME2025 becomes
+// WELCOME202517. Every rewrite is written to the audit trail as an update by
+// "system", and renames are logged so their owners can be told.
+func migrateVoucherCodes(db *gorm.DB) error {
+	if !db.Migrator().HasTable("vouchers") {
+		return nil
+	}
+
+	// Superset of the codes normalization changes; the rest are skipped below
+	var rows []struct {
+		ID        uint
+		Code      string
+		DeletedAt gorm.DeletedAt
+	}
+	err := db.Unscoped().Model(&models.Voucher{}).
+		Select("id", "code", "deleted_at").
+		Where("code ~ ?", "[^A-Z0-9]").
+		Order("id asc").
+		Find(&rows).Error
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// The rewrites are audited, so the audit table must exist by now
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		normalized := 0
+		for _, row := range rows {
+			code := models.NormalizeVoucherCode(row.Code)
+			if code == row.Code {
+				continue
+			}
+
+			taken, err := liveCodeTaken(tx, code, row.ID, !row.DeletedAt.Valid)
+			if err != nil {
+				return err
+			}
+			if taken || len(code) < 3 {
+				id := strconv.FormatUint(uint64(row.ID), 10)
+				code = code[:min(len(code), 50-len(id))] + id
+
+				taken, err := liveCodeTaken(tx, code, row.ID, !row.DeletedAt.Valid)
+				if err != nil {
+					return err
+				}
+				if taken {
+					return fmt.Errorf("voucher %d: code %q collides with another voucher once normalized, rename it by hand", row.ID, row.Code)
+				}
+				log.Printf("Voucher %d code %q collides once normalized, renamed to %q", row.ID, row.Code, code)
+			}
+
+			err = tx.Unscoped().Model(&models.Voucher{}).Where("id = ?", row.ID).UpdateColumn("code", code).Error
+			if err != nil {
+				return err
+			}
+
+			changes, err := json.Marshal(map[string]map[string]string{"code": {"from": row.Code, "to": code}})
+			if err != nil {
+				return err
+			}
+			entry := models.AuditLog{
+				VoucherID:   row.ID,
+				VoucherCode: code,
+				Action:      models.AuditActionUpdate,
+				Actor:       "system",
+				Changes:     string(changes),
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+			normalized++
+		}
+
+		if normalized > 0 {
+			log.Printf("Normalized %d voucher codes", normalized)
+		}
+		return nil
+	})
+}
+
+// liveCodeTaken reports whether a live voucher other than id holds the code.
+// Soft-deleted vouchers do not hold codes, so only live ones are checked.
+func liveCodeTaken(tx *gorm.DB, code string, id uint, live bool) (bool, error) {
+	if !live {
+		return false, nil
+	}
+	var count int64
+	err := tx.Model(&models.Voucher{}).Where("code = ? AND id <> ?", code, id).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/services"
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+type AuditController struct {
+	auditService services.AuditService
+}
+
+func NewAuditController(auditService services.AuditService) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+func (ctrl *AuditController) GetAuditLogs(c *gin.Context) {
+	var query dto.AuditLogListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := ctrl.auditService.GetAuditLogs(query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get audit logs", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Audit logs retrieved successfully", result)
+}
+
+func (ctrl *AuditController) GetVoucherHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid voucher ID", err.Error())
+		return
+	}
+
+	var query dto.AuditLogListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := ctrl.auditService.GetVoucherHistory(uint(id), query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get voucher history", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Voucher history retrieved successfully", result)
+}
+
+// actorFrom reads who is making the request, as set by AuthMiddleware and
+// RequestIDMiddleware, for the audit trail.
+func actorFrom(c *gin.Context) services.Actor {
+	return services.Actor{
+		Username:  c.GetString("username"),
+		RequestID: c.GetString("request_id"),
+	}
+}
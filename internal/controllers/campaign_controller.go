@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/services"
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+type CampaignController struct {
+	campaignService services.CampaignService
+}
+
+func NewCampaignController(campaignService services.CampaignService) *CampaignController {
+	return &CampaignController{campaignService: campaignService}
+}
+
+func (ctrl *CampaignController) GetAllCampaigns(c *gin.Context) {
+	var query dto.CampaignListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := ctrl.campaignService.GetAllCampaigns(query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get campaigns", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Campaigns retrieved successfully", result)
+}
+
+func (ctrl *CampaignController) GetCampaignByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid campaign ID", err.Error())
+		return
+	}
+
+	result, err := ctrl.campaignService.GetCampaignByID(uint(id))
+	if err != nil {
+		respondCampaignError(c, "Failed to get campaign", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Campaign retrieved successfully", result)
+}
+
+// CreateCampaign defaults the owner to the authenticated user.
+func (ctrl *CampaignController) CreateCampaign(c *gin.Context) {
+	var req dto.CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		return
+	}
+	if req.Owner == "" {
+		req.Owner = c.GetString("username")
+	}
+
+	result, err := ctrl.campaignService.CreateCampaign(req)
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error(), nil)
+		return
+	}
+
+	utils.CreatedResponse(c, "Campaign created successfully", result)
+}
+
+func (ctrl *CampaignController) UpdateCampaign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid campaign ID", err.Error())
+		return
+	}
+
+	var req dto.UpdateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := ctrl.campaignService.UpdateCampaign(uint(id), req)
+	if err != nil {
+		respondCampaignError(c, "Failed to update campaign", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Campaign updated successfully", result)
+}
+
+func (ctrl *CampaignController) DeleteCampaign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid campaign ID", err.Error())
+		return
+	}
+
+	if err := ctrl.campaignService.DeleteCampaign(uint(id), actorFrom(c)); err != nil {
+		respondCampaignError(c, "Failed to delete campaign", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Campaign deleted successfully", nil)
+}
+
+func (ctrl *CampaignController) ActivateCampaign(c *gin.Context) {
+	ctrl.setCampaignStatus(c, models.CampaignStatusActive, "Campaign activated successfully")
+}
+
+func (ctrl *CampaignController) PauseCampaign(c *gin.Context) {
+	ctrl.setCampaignStatus(c, models.CampaignStatusPaused, "Campaign paused successfully")
+}
+
+func (ctrl *CampaignController) setCampaignStatus(c *gin.Context, status, message string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid campaign ID", err.Error())
+		return
+	}
+
+	result, err := ctrl.campaignService.SetCampaignStatus(uint(id), status, actorFrom(c))
+	if err != nil {
+		respondCampaignError(c, "Failed to change campaign status", err)
+		return
+	}
+
+	utils.SuccessResponse(c, message, result)
+}
+
+func respondCampaignError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrCampaignNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, services.ErrInvalidCampaignDates), errors.Is(err, services.ErrCampaignStatusUnknown):
+		utils.BadRequestResponse(c, err.Error(), nil)
+	case errors.Is(err, services.ErrBudgetBelowUsage):
+		utils.ConflictResponse(c, err.Error(), nil)
+	default:
+		utils.InternalServerErrorResponse(c, message, err.Error())
+	}
+}
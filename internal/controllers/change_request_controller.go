@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/services"
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+type ChangeRequestController struct {
+	voucherService services.VoucherService
+}
+
+func NewChangeRequestController(voucherService services.VoucherService) *ChangeRequestController {
+	return &ChangeRequestController{voucherService: voucherService}
+}
+
+func (ctrl *ChangeRequestController) GetAllChangeRequests(c *gin.Context) {
+	var query dto.ChangeRequestListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := ctrl.voucherService.GetAllChangeRequests(query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get change requests", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Change requests retrieved successfully", result)
+}
+
+func (ctrl *ChangeRequestController) GetChangeRequestByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid change request ID", err.Error())
+		return
+	}
+
+	result, err := ctrl.voucherService.GetChangeRequestByID(uint(id))
+	if err != nil {
+		respondChangeRequestError(c, "Failed to get change request", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Change request retrieved successfully", result)
+}
+
+// ApproveChangeRequest applies the change as the authenticated user, who must
+// not be the one who requested it.
+func (ctrl *ChangeRequestController) ApproveChangeRequest(c *gin.Context) {
+	id, req, ok := bindReview(c)
+	if !ok {
+		return
+	}
+
+	result, err := ctrl.voucherService.ApproveChangeRequest(id, actorFrom(c), req)
+	if err != nil {
+		respondChangeRequestError(c, "Failed to approve change request", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Change request approved successfully", result)
+}
+
+func (ctrl *ChangeRequestController) RejectChangeRequest(c *gin.Context) {
+	id, req, ok := bindReview(c)
+	if !ok {
+		return
+	}
+
+	result, err := ctrl.voucherService.RejectChangeRequest(id, actorFrom(c), req)
+	if err != nil {
+		respondChangeRequestError(c, "Failed to reject change request", err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Change request rejected successfully", result)
+}
+
+// bindReview reads the change request ID and the optional review note.
+func bindReview(c *gin.Context) (uint, dto.ReviewChangeRequest, bool) {
+	var req dto.ReviewChangeRequest
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid change request ID", err.Error())
+		return 0, req, false
+	}
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request body", err.Error())
+			return 0, req, false
+		}
+	}
+
+	return uint(id), req, true
+}
+
+func respondChangeRequestError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrChangeRequestNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, services.ErrSelfReview):
+		utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrChangeNotPending), errors.Is(err, services.ErrVoucherCodeConflict), errors.Is(err, utils.ErrPatchTestFailed):
+		utils.ConflictResponse(c, err.Error(), nil)
+	case errors.Is(err, services.ErrVoucherNotFound):
+		utils.ConflictResponse(c, "the voucher this change applies to no longer exists", nil)
+	default:
+		utils.BadRequestResponse(c, message, err.Error())
+	}
+}
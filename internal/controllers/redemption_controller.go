@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/services"
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+type RedemptionController struct {
+	redemptionService services.RedemptionService
+}
+
+func NewRedemptionController(redemptionService services.RedemptionService) *RedemptionController {
+	return &RedemptionController{redemptionService: redemptionService}
+}
+
+func (ctrl *RedemptionController) ValidateVoucher(c *gin.Context) {
+	var req dto.ValidateVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := ctrl.redemptionService.ValidateVoucher(req)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to validate voucher", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Voucher validated successfully", result)
+}
+
+func (ctrl *RedemptionController) CombineVouchers(c *gin.Context) {
+	var req dto.CombineVouchersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := ctrl.redemptionService.CombineVouchers(req)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to combine vouchers", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Voucher combination calculated successfully", result)
+}
+
+func (ctrl *RedemptionController) RedeemVoucher(c *gin.Context) {
+	var req dto.RedeemVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := ctrl.redemptionService.RedeemVoucher(req, actorFrom(c))
+	if err != nil {
+		var unavailable *services.VoucherUnavailableError
+		switch {
+		case errors.Is(err, services.ErrCustomerRefRequired):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		case errors.Is(err, services.ErrVoucherNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.As(err, &unavailable):
+			utils.ConflictResponse(c, err.Error(), gin.H{"reason": unavailable.Reason})
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to redeem voucher", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Voucher redeemed successfully", result)
+}
+
+func (ctrl *RedemptionController) ReserveVoucher(c *gin.Context) {
+	var req dto.RedeemVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := ctrl.redemptionService.ReserveVoucher(req, actorFrom(c))
+	if err != nil {
+		var unavailable *services.VoucherUnavailableError
+		switch {
+		case errors.Is(err, services.ErrCustomerRefRequired):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		case errors.Is(err, services.ErrVoucherNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.As(err, &unavailable):
+			utils.ConflictResponse(c, err.Error(), gin.H{"reason": unavailable.Reason})
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to reserve voucher", err.Error())
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Voucher reserved successfully", result)
+}
+
+func (ctrl *RedemptionController) ConfirmReservation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid redemption ID", err.Error())
+		return
+	}
+
+	result, err := ctrl.redemptionService.ConfirmReservation(uint(id), actorFrom(c))
+	if err != nil {
+		respondReservationError(c, err, "Failed to confirm reservation")
+		return
+	}
+
+	utils.SuccessResponse(c, "Reservation confirmed successfully", result)
+}
+
+func (ctrl *RedemptionController) ReleaseReservation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid redemption ID", err.Error())
+		return
+	}
+
+	result, err := ctrl.redemptionService.ReleaseReservation(uint(id), actorFrom(c))
+	if err != nil {
+		respondReservationError(c, err, "Failed to release reservation")
+		return
+	}
+
+	utils.SuccessResponse(c, "Reservation released successfully", result)
+}
+
+func (ctrl *RedemptionController) GetRedemptionsByVoucher(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid voucher ID", err.Error())
+		return
+	}
+
+	var query dto.RedemptionListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := ctrl.redemptionService.GetRedemptionsByVoucher(uint(id), query)
+	if err != nil {
+		if errors.Is(err, services.ErrVoucherNotFound) {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get redemptions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Redemptions retrieved successfully", result)
+}
+
+func (ctrl *RedemptionController) ReverseRedemption(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid redemption ID", err.Error())
+		return
+	}
+
+	// The reason is optional, so an empty body is accepted
+	var req dto.ReverseRedemptionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	result, err := ctrl.redemptionService.ReverseRedemption(uint(id), req, actorFrom(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRedemptionNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.Is(err, services.ErrRedemptionAlreadyReversed),
+			errors.Is(err, services.ErrRedemptionNotRedeemed):
+			utils.ConflictResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to reverse redemption", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Redemption reversed successfully", result)
+}
+
+func respondReservationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrRedemptionNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, services.ErrRedemptionNotReserved),
+		errors.Is(err, services.ErrReservationExpired):
+		utils.ConflictResponse(c, err.Error(), nil)
+	default:
+		utils.InternalServerErrorResponse(c, message, err.Error())
+	}
+}
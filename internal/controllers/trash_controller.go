@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/services"
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+type TrashController struct {
+	voucherService services.VoucherService
+}
+
+func NewTrashController(voucherService services.VoucherService) *TrashController {
+	return &TrashController{voucherService: voucherService}
+}
+
+func (ctrl *TrashController) GetTrash(c *gin.Context) {
+	var query dto.TrashListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := ctrl.voucherService.GetTrash(query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get trashed vouchers", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Trashed vouchers retrieved successfully", result)
+}
+
+// RestoreVoucher takes an optional body with a new code for the voucher.
+func (ctrl *TrashController) RestoreVoucher(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid voucher ID", err.Error())
+		return
+	}
+
+	var req dto.RestoreVoucherRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	result, err := ctrl.voucherService.RestoreVoucher(uint(id), req, actorFrom(c))
+	if err != nil {
+		var conflict *services.VoucherCodeConflictError
+		switch {
+		case errors.Is(err, services.ErrTrashedVoucherNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.As(err, &conflict):
+			details := gin.H{"code": conflict.Code}
+			if conflict.VoucherID > 0 {
+				details["conflicting_voucher_id"] = conflict.VoucherID
+			}
+			utils.ConflictResponse(c, err.Error(), details)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to restore voucher", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Voucher restored successfully", result)
+}
+
+func (ctrl *TrashController) PurgeVoucher(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid voucher ID", err.Error())
+		return
+	}
+
+	if err := ctrl.voucherService.PurgeVoucher(uint(id), actorFrom(c)); err != nil {
+		if errors.Is(err, services.ErrTrashedVoucherNotFound) {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to purge voucher", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Voucher purged successfully", nil)
+}
@@ -3,12 +3,17 @@ package controllers
 import (
 	"bytes"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
 	"github.com/rifqi142/indico-be/internal/services"
 	"github.com/rifqi142/indico-be/internal/utils"
 )
@@ -30,6 +35,10 @@ func (ctrl *VoucherController) GetAllVouchers(c *gin.Context) {
 
 	result, err := ctrl.voucherService.GetAllVouchers(query)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			utils.BadRequestResponse(c, "Invalid cursor", err.Error())
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to get vouchers", err.Error())
 		return
 	}
@@ -50,6 +59,12 @@ func (ctrl *VoucherController) GetVoucherByID(c *gin.Context) {
 		return
 	}
 
+	etag := setVoucherETag(c, result)
+	if utils.IfNoneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	utils.SuccessResponse(c, "Voucher retrieved successfully", result)
 }
 
@@ -60,12 +75,21 @@ func (ctrl *VoucherController) CreateVoucher(c *gin.Context) {
 		return
 	}
 
-	result, err := ctrl.voucherService.CreateVoucher(req)
+	result, pending, err := ctrl.voucherService.CreateVoucher(req, actorFrom(c))
 	if err != nil {
+		if errors.Is(err, services.ErrVoucherCodeConflict) {
+			utils.ConflictResponse(c, err.Error(), nil)
+			return
+		}
 		utils.BadRequestResponse(c, err.Error(), nil)
 		return
 	}
+	if pending != nil {
+		utils.AcceptedResponse(c, "Voucher is waiting for approval", pending)
+		return
+	}
 
+	setVoucherETag(c, result)
 	utils.CreatedResponse(c, "Voucher created successfully", result)
 }
 
@@ -76,18 +100,93 @@ func (ctrl *VoucherController) UpdateVoucher(c *gin.Context) {
 		return
 	}
 
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
 	var req dto.UpdateVoucherRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request body", err.Error())
 		return
 	}
 
-	result, err := ctrl.voucherService.UpdateVoucher(uint(id), req)
+	result, pending, err := ctrl.voucherService.UpdateVoucher(uint(id), req, ifMatch, actorFrom(c))
 	if err != nil {
-		utils.BadRequestResponse(c, err.Error(), nil)
+		switch {
+		case errors.Is(err, services.ErrVoucherNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.Is(err, services.ErrVoucherModified):
+			utils.PreconditionFailedResponse(c, err.Error())
+		case errors.Is(err, services.ErrVoucherCodeConflict):
+			utils.ConflictResponse(c, err.Error(), nil)
+		default:
+			utils.BadRequestResponse(c, err.Error(), nil)
+		}
+		return
+	}
+	if pending != nil {
+		utils.AcceptedResponse(c, "Voucher update is waiting for approval", pending)
+		return
+	}
+
+	setVoucherETag(c, result)
+	utils.SuccessResponse(c, "Voucher updated successfully", result)
+}
+
+// PatchVoucher takes an RFC 7396 merge patch (application/merge-patch+json,
+// or plain application/json) or an RFC 6902 JSON Patch
+// (application/json-patch+json).
+func (ctrl *VoucherController) PatchVoucher(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid voucher ID", err.Error())
+		return
+	}
+
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var patch dto.VoucherPatch
+	switch c.ContentType() {
+	case "application/merge-patch+json", "application/json":
+		patch.Format = dto.PatchFormatMerge
+	case "application/json-patch+json":
+		patch.Format = dto.PatchFormatJSON
+	default:
+		utils.UnsupportedMediaTypeResponse(c, "Use application/merge-patch+json or application/json-patch+json")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil || !json.Valid(body) {
+		utils.BadRequestResponse(c, "Invalid request body", "body must be a JSON document")
 		return
 	}
+	patch.Patch = body
 
+	result, pending, err := ctrl.voucherService.PatchVoucher(uint(id), patch, ifMatch, actorFrom(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrVoucherNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.Is(err, services.ErrVoucherModified):
+			utils.PreconditionFailedResponse(c, err.Error())
+		case errors.Is(err, services.ErrVoucherCodeConflict), errors.Is(err, utils.ErrPatchTestFailed):
+			utils.ConflictResponse(c, err.Error(), nil)
+		default:
+			utils.BadRequestResponse(c, err.Error(), nil)
+		}
+		return
+	}
+	if pending != nil {
+		utils.AcceptedResponse(c, "Voucher update is waiting for approval", pending)
+		return
+	}
+
+	setVoucherETag(c, result)
 	utils.SuccessResponse(c, "Voucher updated successfully", result)
 }
 
@@ -98,14 +197,124 @@ func (ctrl *VoucherController) DeleteVoucher(c *gin.Context) {
 		return
 	}
 
-	if err := ctrl.voucherService.DeleteVoucher(uint(id)); err != nil {
-		utils.NotFoundResponse(c, err.Error())
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.voucherService.DeleteVoucher(uint(id), ifMatch, actorFrom(c)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrVoucherNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.Is(err, services.ErrVoucherModified):
+			utils.PreconditionFailedResponse(c, err.Error())
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to delete voucher", err.Error())
+		}
 		return
 	}
 
 	utils.SuccessResponse(c, "Voucher deleted successfully", nil)
 }
 
+// BulkUpdateVouchers applies one action to the vouchers listed in the body's
+// ids, or to those matching the list filters in the query string.
+func (ctrl *VoucherController) BulkUpdateVouchers(c *gin.Context) {
+	var filter dto.VoucherFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	var req dto.BulkVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := ctrl.voucherService.BulkUpdateVouchers(req, filter, actorFrom(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBulkTargets), errors.Is(err, services.ErrBulkTooLarge), errors.Is(err, services.ErrBulkParams):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to apply bulk action", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, "Bulk action applied", result)
+}
+
+func (ctrl *VoucherController) GenerateCodes(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid voucher ID", err.Error())
+		return
+	}
+
+	var req dto.GenerateCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := ctrl.voucherService.GenerateCodes(uint(id), req, actorFrom(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrVoucherNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.Is(err, services.ErrCodeGenerationFailed):
+			utils.ConflictResponse(c, err.Error(), nil)
+		default:
+			utils.BadRequestResponse(c, err.Error(), nil)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Voucher codes generated successfully", result)
+}
+
+func (ctrl *VoucherController) PublishVoucher(c *gin.Context) {
+	ctrl.transitionVoucher(c, models.TransitionPublish, "Voucher published successfully")
+}
+
+func (ctrl *VoucherController) PauseVoucher(c *gin.Context) {
+	ctrl.transitionVoucher(c, models.TransitionPause, "Voucher paused successfully")
+}
+
+func (ctrl *VoucherController) ResumeVoucher(c *gin.Context) {
+	ctrl.transitionVoucher(c, models.TransitionResume, "Voucher resumed successfully")
+}
+
+func (ctrl *VoucherController) ArchiveVoucher(c *gin.Context) {
+	ctrl.transitionVoucher(c, models.TransitionArchive, "Voucher archived successfully")
+}
+
+func (ctrl *VoucherController) transitionVoucher(c *gin.Context, transition models.VoucherTransition, message string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid voucher ID", err.Error())
+		return
+	}
+
+	result, err := ctrl.voucherService.TransitionVoucher(uint(id), transition, actorFrom(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrVoucherNotFound):
+			utils.NotFoundResponse(c, err.Error())
+		case errors.Is(err, services.ErrInvalidTransition):
+			utils.ConflictResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to change voucher status", err.Error())
+		}
+		return
+	}
+
+	setVoucherETag(c, result)
+	utils.SuccessResponse(c, message, result)
+}
+
 func (ctrl *VoucherController) UploadCSV(c *gin.Context) {
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -126,7 +335,7 @@ func (ctrl *VoucherController) UploadCSV(c *gin.Context) {
 	defer src.Close()
 
 	// Process CSV
-	result, err := ctrl.voucherService.ImportFromCSV(src)
+	result, err := ctrl.voucherService.ImportFromCSV(src, actorFrom(c))
 	if err != nil {
 		utils.BadRequestResponse(c, err.Error(), nil)
 		return
@@ -135,8 +344,15 @@ func (ctrl *VoucherController) UploadCSV(c *gin.Context) {
 	utils.SuccessResponse(c, "CSV uploaded successfully", result)
 }
 
+// ExportCSV exports the vouchers matching the list filters in the query string.
 func (ctrl *VoucherController) ExportCSV(c *gin.Context) {
-	data, err := ctrl.voucherService.ExportToCSV()
+	var filter dto.VoucherFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	data, err := ctrl.voucherService.ExportToCSV(filter)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to export vouchers", err.Error())
 		return
@@ -160,6 +376,23 @@ func (ctrl *VoucherController) ExportCSV(c *gin.Context) {
 	c.Data(200, "text/csv", buf.Bytes())
 }
 
+// requireIfMatch returns the request's If-Match header, answering 428 when it
+// is missing, so a write always names the version of the voucher it is based on.
+func requireIfMatch(c *gin.Context) (string, bool) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		utils.PreconditionRequiredResponse(c, "If-Match header is required, send the ETag from GET /vouchers/get-by-id/:id")
+		return "", false
+	}
+	return ifMatch, true
+}
+
+func setVoucherETag(c *gin.Context, voucher *dto.VoucherResponse) string {
+	etag := dto.VoucherETag(voucher.Version, voucher.Status)
+	c.Header("ETag", etag)
+	return etag
+}
+
 func isCSVFile(filename string) bool {
 	return len(filename) > 4 && filename[len(filename)-4:] == ".csv"
 }
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogListQuery struct {
+	Page      int       `form:"page" binding:"omitempty,min=1"`
+	PageSize  int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	VoucherID uint      `form:"voucher_id"`
+	Code      string    `form:"code"`
+	Actor     string    `form:"actor"`
+	Action    string    `form:"action"`
+	RequestID string    `form:"request_id"`
+	Field     string    `form:"field"`
+	From      time.Time `form:"from"`
+	To        time.Time `form:"to"`
+}
+
+// AuditLogResponse keeps the exact timestamp, since several entries often
+// share a day. Changes maps each changed field to {"from": ..., "to": ...}.
+type AuditLogResponse struct {
+	ID              uint            `json:"id"`
+	VoucherID       uint            `json:"voucher_id"`
+	VoucherCode     string          `json:"voucher_code"`
+	RedemptionID    *uint           `json:"redemption_id,omitempty"`
+	ChangeRequestID *uint           `json:"change_request_id,omitempty"`
+	Action          string          `json:"action"`
+	Actor           string          `json:"actor"`
+	RequestID       string          `json:"request_id"`
+	Changes         json.RawMessage `json:"changes"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+type AuditLogListResponse struct {
+	Data       []AuditLogResponse `json:"data"`
+	Pagination PaginationMeta     `json:"pagination"`
+}
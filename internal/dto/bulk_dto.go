@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/models"
+)
+
+// BulkVoucherRequest applies one action to many vouchers, chosen either by
+// IDs or by the list filters in the query string. ValidUntil is required for
+// extend_validity, Discount and DiscountType for change_discount.
+type BulkVoucherRequest struct {
+	Action       string         `json:"action" binding:"required,oneof=activate deactivate delete extend_validity change_discount"`
+	IDs          []uint         `json:"ids" binding:"omitempty,max=1000,dive,min=1"`
+	ValidUntil   time.Time      `json:"valid_until"`
+	Discount     models.Decimal `json:"discount" binding:"omitempty,gt=0"`
+	DiscountType string         `json:"discount_type" binding:"omitempty,oneof=percentage fixed"`
+	Atomic       bool           `json:"atomic"`
+}
+
+// BulkItemResult is the outcome for one voucher: updated, skipped (nothing
+// to change), failed, or rolled_back when an atomic batch was undone.
+type BulkItemResult struct {
+	ID     uint   `json:"id"`
+	Code   string `json:"code,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BulkVoucherResponse struct {
+	Action     string           `json:"action"`
+	Matched    int              `json:"matched"`
+	Updated    int              `json:"updated"`
+	Skipped    int              `json:"skipped"`
+	Failed     int              `json:"failed"`
+	RolledBack bool             `json:"rolled_back"`
+	Results    []BulkItemResult `json:"results"`
+}
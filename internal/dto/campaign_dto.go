@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+type CreateCampaignRequest struct {
+	Name        string       `json:"name" binding:"required,min=3,max=255"`
+	Description string       `json:"description"`
+	Owner       string       `json:"owner" binding:"max=100"`
+	StartsAt    time.Time    `json:"starts_at" binding:"required"`
+	EndsAt      time.Time    `json:"ends_at" binding:"required,gtfield=StartsAt"`
+	Currency    string       `json:"currency" binding:"omitempty,iso4217"`
+	Budget      models.Money `json:"budget" binding:"min=0"`
+}
+
+type UpdateCampaignRequest struct {
+	Name        string        `json:"name" binding:"omitempty,min=3,max=255"`
+	Description *string       `json:"description"`
+	Owner       string        `json:"owner" binding:"omitempty,max=100"`
+	StartsAt    time.Time     `json:"starts_at"`
+	EndsAt      time.Time     `json:"ends_at"`
+	Budget      *models.Money `json:"budget" binding:"omitempty,min=0"`
+}
+
+type CampaignResponse struct {
+	ID              uint               `json:"id"`
+	Name            string             `json:"name"`
+	Description     string             `json:"description"`
+	Owner           string             `json:"owner"`
+	StartsAt        utils.ReadableTime `json:"starts_at"`
+	EndsAt          utils.ReadableTime `json:"ends_at"`
+	Currency        string             `json:"currency"`
+	Budget          models.Money       `json:"budget"`
+	BudgetUsed      models.Money       `json:"budget_used"`
+	BudgetReserved  models.Money       `json:"budget_reserved"`
+	RemainingBudget *models.Money      `json:"remaining_budget"`
+	Status          string             `json:"status"`
+	VoucherCount    int64              `json:"voucher_count"`
+	CreatedAt       utils.ReadableTime `json:"created_at"`
+	UpdatedAt       utils.ReadableTime `json:"updated_at"`
+}
+
+type CampaignListQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Search   string `form:"search"`
+	Owner    string `form:"owner"`
+	Status   string `form:"status" binding:"omitempty,oneof=active paused"`
+}
+
+type CampaignListResponse struct {
+	Data       []CampaignResponse `json:"data"`
+	Pagination PaginationMeta     `json:"pagination"`
+}
+
+// CampaignStatusResponse is returned by activate and pause, with the number
+// of vouchers that were resumed or paused along with the campaign.
+type CampaignStatusResponse struct {
+	Campaign         CampaignResponse `json:"campaign"`
+	VouchersAffected int64            `json:"vouchers_affected"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+type ReviewChangeRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+type ChangeRequestListQuery struct {
+	Page        int    `form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status      string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+	Action      string `form:"action" binding:"omitempty,oneof=create update patch"`
+	VoucherID   uint   `form:"voucher_id"`
+	RequestedBy string `form:"requested_by"`
+}
+
+// ChangeRequestResponse shows a pending or reviewed voucher change. Payload
+// is the create or update body as it was submitted, and Voucher is only set
+// on the response to an approval.
+type ChangeRequestResponse struct {
+	ID          uint               `json:"id"`
+	VoucherID   *uint              `json:"voucher_id"`
+	Action      string             `json:"action"`
+	Status      string             `json:"status"`
+	Reasons     []string           `json:"reasons"`
+	Payload     json.RawMessage    `json:"payload"`
+	RequestedBy string             `json:"requested_by"`
+	ReviewedBy  string             `json:"reviewed_by"`
+	ReviewNote  string             `json:"review_note"`
+	ReviewedAt  utils.ReadableTime `json:"reviewed_at"`
+	CreatedAt   utils.ReadableTime `json:"created_at"`
+	UpdatedAt   utils.ReadableTime `json:"updated_at"`
+	Voucher     *VoucherResponse   `json:"voucher,omitempty"`
+}
+
+type ChangeRequestListResponse struct {
+	Data       []ChangeRequestResponse `json:"data"`
+	Pagination PaginationMeta          `json:"pagination"`
+}
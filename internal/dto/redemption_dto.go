@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+type CartItem struct {
+	SKU       string       `json:"sku" binding:"required,max=100"`
+	Category  string       `json:"category" binding:"max=100"`
+	Quantity  int          `json:"quantity" binding:"required,min=1"`
+	UnitPrice models.Money `json:"unit_price" binding:"min=0"`
+}
+
+type RedeemVoucherRequest struct {
+	Code        string       `json:"code" binding:"required"`
+	CustomerRef string       `json:"customer_ref" binding:"required,max=100"`
+	OrderRef    string       `json:"order_ref" binding:"required,max=100"`
+	OrderAmount models.Money `json:"order_amount" binding:"omitempty,min=0"`
+	Currency    string       `json:"currency" binding:"omitempty,iso4217"`
+	ItemCount   int          `json:"item_count" binding:"omitempty,min=0"`
+	Items       []CartItem   `json:"items" binding:"omitempty,dive"`
+}
+
+type ValidateVoucherRequest struct {
+	Code        string       `json:"code" binding:"required"`
+	CartAmount  models.Money `json:"cart_amount" binding:"min=0"`
+	Currency    string       `json:"currency" binding:"omitempty,iso4217"`
+	ItemCount   int          `json:"item_count" binding:"omitempty,min=0"`
+	CustomerRef string       `json:"customer_ref" binding:"max=100"`
+	Items       []CartItem   `json:"items" binding:"omitempty,dive"`
+}
+
+type ValidateVoucherResponse struct {
+	Code           string       `json:"code"`
+	Name           string       `json:"name,omitempty"`
+	Valid          bool         `json:"valid"`
+	Reason         string       `json:"reason,omitempty"`
+	Message        string       `json:"message,omitempty"`
+	Currency       string       `json:"currency,omitempty"`
+	CartAmount     models.Money `json:"cart_amount"`
+	EligibleAmount models.Money `json:"eligible_amount"`
+	DiscountAmount models.Money `json:"discount_amount"`
+	FinalAmount    models.Money `json:"final_amount"`
+}
+
+type CombineVouchersRequest struct {
+	Codes       []string     `json:"codes" binding:"required,min=1,max=10,dive,required"`
+	CartAmount  models.Money `json:"cart_amount" binding:"min=0"`
+	Currency    string       `json:"currency" binding:"omitempty,iso4217"`
+	ItemCount   int          `json:"item_count" binding:"omitempty,min=0"`
+	CustomerRef string       `json:"customer_ref" binding:"max=100"`
+	Items       []CartItem   `json:"items" binding:"omitempty,dive"`
+}
+
+type AppliedVoucher struct {
+	Code           string       `json:"code"`
+	Name           string       `json:"name"`
+	DiscountAmount models.Money `json:"discount_amount"`
+}
+
+type RejectedVoucher struct {
+	Code    string `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type CombineVouchersResponse struct {
+	Currency      string            `json:"currency"`
+	CartAmount    models.Money      `json:"cart_amount"`
+	Applied       []AppliedVoucher  `json:"applied"`
+	Rejected      []RejectedVoucher `json:"rejected"`
+	TotalDiscount models.Money      `json:"total_discount"`
+	FinalAmount   models.Money      `json:"final_amount"`
+}
+
+type ReverseRedemptionRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type RedemptionResponse struct {
+	ID             uint                   `json:"id"`
+	VoucherID      uint                   `json:"voucher_id"`
+	VoucherCode    string                 `json:"voucher_code"`
+	CustomerRef    string                 `json:"customer_ref"`
+	OrderRef       string                 `json:"order_ref"`
+	OrderAmount    models.Money           `json:"order_amount"`
+	DiscountAmount models.Money           `json:"discount_amount"`
+	Currency       string                 `json:"currency"`
+	Status         string                 `json:"status"`
+	ReservedAt     utils.ReadableDateTime `json:"reserved_at"`
+	ExpiresAt      utils.ReadableDateTime `json:"expires_at"`
+	RedeemedAt     utils.ReadableTime     `json:"redeemed_at"`
+	ReleasedAt     utils.ReadableTime     `json:"released_at"`
+	ReversedAt     utils.ReadableTime     `json:"reversed_at"`
+	ReversalReason string                 `json:"reversal_reason,omitempty"`
+}
+
+type ReserveVoucherResponse struct {
+	Reservation    RedemptionResponse `json:"reservation"`
+	Voucher        VoucherResponse    `json:"voucher"`
+	RemainingUsage int                `json:"remaining_usage"`
+}
+
+type RedeemVoucherResponse struct {
+	Redemption     RedemptionResponse `json:"redemption"`
+	Voucher        VoucherResponse    `json:"voucher"`
+	RemainingUsage int                `json:"remaining_usage"`
+}
+
+type RedemptionListQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status   string `form:"status" binding:"omitempty,oneof=reserved redeemed reversed released expired"`
+}
+
+type RedemptionListResponse struct {
+	Data       []RedemptionResponse `json:"data"`
+	Pagination PaginationMeta       `json:"pagination"`
+}
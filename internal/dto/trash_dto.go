@@ -0,0 +1,28 @@
+package dto
+
+import "github.com/rifqi142/indico-be/internal/utils"
+
+type TrashListQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Search   string `form:"search"`
+}
+
+// RestoreVoucherRequest optionally gives the voucher a new code, for when its
+// old one has been taken since it was deleted.
+type RestoreVoucherRequest struct {
+	Code string `json:"code" binding:"omitempty,min=3,max=50"`
+}
+
+// TrashedVoucherResponse is a soft-deleted voucher. PurgeAt is when the
+// retention policy will delete it for good, or null if it never will.
+type TrashedVoucherResponse struct {
+	VoucherResponse
+	DeletedAt utils.ReadableTime `json:"deleted_at"`
+	PurgeAt   utils.ReadableTime `json:"purge_at"`
+}
+
+type TrashListResponse struct {
+	Data       []TrashedVoucherResponse `json:"data"`
+	Pagination PaginationMeta           `json:"pagination"`
+}
@@ -1,55 +1,237 @@
 package dto
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
+	"github.com/rifqi142/indico-be/internal/models"
 	"github.com/rifqi142/indico-be/internal/utils"
 )
 
+type VoucherTargetRequest struct {
+	Type  string `json:"type" binding:"required,oneof=sku category"`
+	Value string `json:"value" binding:"required,max=100"`
+	Mode  string `json:"mode" binding:"omitempty,oneof=include exclude"`
+}
+
+type VoucherTargetResponse struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Mode  string `json:"mode"`
+}
+
+// VoucherScheduleRequest is a recurring window in WIB (Asia/Jakarta). Days
+// defaults to every day and StartTime/EndTime ("HH:MM") to the whole day.
+type VoucherScheduleRequest struct {
+	Days      []string `json:"days" binding:"omitempty,dive,oneof=mon tue wed thu fri sat sun"`
+	StartTime string   `json:"start_time" binding:"omitempty,datetime=15:04,required_with=EndTime"`
+	EndTime   string   `json:"end_time" binding:"omitempty,datetime=15:04,required_with=StartTime"`
+}
+
+type VoucherScheduleResponse struct {
+	Days      []string `json:"days"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+	Timezone  string   `json:"timezone"`
+}
+
 type CreateVoucherRequest struct {
-	Code        string    `json:"code" binding:"required,min=3,max=50"`
-	Name        string    `json:"name" binding:"required,min=3,max=255"`
-	Description string    `json:"description"`
-	Discount    float64   `json:"discount" binding:"required,min=0,max=100"`
-	MaxUsage    int       `json:"max_usage" binding:"required,min=1"`
-	ValidFrom   time.Time `json:"valid_from" binding:"required"`
-	ValidUntil  time.Time `json:"valid_until" binding:"required,gtfield=ValidFrom"`
-	IsActive    *bool     `json:"is_active"`
+	Code                string                   `json:"code" binding:"required,min=3,max=50"`
+	Name                string                   `json:"name" binding:"required,min=3,max=255"`
+	Description         string                   `json:"description"`
+	Discount            models.Decimal           `json:"discount" binding:"required,gt=0"`
+	DiscountType        string                   `json:"discount_type" binding:"omitempty,oneof=percentage fixed"`
+	Currency            string                   `json:"currency" binding:"omitempty,iso4217"`
+	MaxDiscount         *models.Money            `json:"max_discount" binding:"omitempty,min=0"`
+	MinOrderAmount      *models.Money            `json:"min_order_amount" binding:"omitempty,min=0"`
+	MinItemCount        *int                     `json:"min_item_count" binding:"omitempty,min=0"`
+	MaxUsage            int                      `json:"max_usage" binding:"required,min=1"`
+	MaxUsagePerCustomer *int                     `json:"max_usage_per_customer" binding:"omitempty,min=0"`
+	ValidFrom           time.Time                `json:"valid_from" binding:"required"`
+	ValidUntil          time.Time                `json:"valid_until" binding:"required,gtfield=ValidFrom"`
+	Status              string                   `json:"status" binding:"omitempty,oneof=draft active paused"`
+	StackingMode        string                   `json:"stacking_mode" binding:"omitempty,oneof=exclusive stackable"`
+	ExclusivityGroup    string                   `json:"exclusivity_group" binding:"max=50"`
+	CampaignID          *uint                    `json:"campaign_id"`
+	Targets             []VoucherTargetRequest   `json:"targets" binding:"omitempty,dive"`
+	Schedules           []VoucherScheduleRequest `json:"schedules" binding:"omitempty,dive"`
 }
 
 type UpdateVoucherRequest struct {
-	Code 	  string    `json:"code" binding:"omitempty,min=3,max=50"`
-	Name        string    `json:"name" binding:"omitempty,min=3,max=255"`
-	Description string    `json:"description"`
-	Discount    float64   `json:"discount" binding:"omitempty,min=0,max=100"`
-	MaxUsage    int       `json:"max_usage" binding:"omitempty,min=1"`
-	ValidFrom   time.Time `json:"valid_from"`
-	ValidUntil  time.Time `json:"valid_until"`
-	IsActive    *bool     `json:"is_active"`
+	Code                string                    `json:"code" binding:"omitempty,min=3,max=50"`
+	Name                string                    `json:"name" binding:"omitempty,min=3,max=255"`
+	Description         string                    `json:"description"`
+	Discount            models.Decimal            `json:"discount" binding:"omitempty,gt=0"`
+	DiscountType        string                    `json:"discount_type" binding:"omitempty,oneof=percentage fixed"`
+	Currency            string                    `json:"currency" binding:"omitempty,iso4217"`
+	MaxDiscount         *models.Money             `json:"max_discount" binding:"omitempty,min=0"`
+	MinOrderAmount      *models.Money             `json:"min_order_amount" binding:"omitempty,min=0"`
+	MinItemCount        *int                      `json:"min_item_count" binding:"omitempty,min=0"`
+	MaxUsage            int                       `json:"max_usage" binding:"omitempty,min=1"`
+	MaxUsagePerCustomer *int                      `json:"max_usage_per_customer" binding:"omitempty,min=0"`
+	ValidFrom           time.Time                 `json:"valid_from"`
+	ValidUntil          time.Time                 `json:"valid_until"`
+	StackingMode        string                    `json:"stacking_mode" binding:"omitempty,oneof=exclusive stackable"`
+	ExclusivityGroup    *string                   `json:"exclusivity_group" binding:"omitempty,max=50"`
+	CampaignID          *uint                     `json:"campaign_id"`
+	Targets             *[]VoucherTargetRequest   `json:"targets" binding:"omitempty,dive"`
+	Schedules           *[]VoucherScheduleRequest `json:"schedules" binding:"omitempty,dive"`
+}
+
+const (
+	PatchFormatMerge = "merge-patch"
+	PatchFormatJSON  = "json-patch"
+)
+
+// VoucherPatch is a PATCH body: an RFC 7396 merge patch or an RFC 6902 JSON
+// Patch, applied to the voucher's VoucherDocument.
+type VoucherPatch struct {
+	Format string          `json:"format"`
+	Patch  json.RawMessage `json:"patch"`
+}
+
+// VoucherDocument holds every field a PATCH may change, in the shape of the
+// create request. Unlike UpdateVoucherRequest, zero values are real values,
+// and the whole document is validated after the patch is applied.
+type VoucherDocument struct {
+	Code                string                   `json:"code" binding:"required,min=3,max=50"`
+	Name                string                   `json:"name" binding:"required,min=3,max=255"`
+	Description         string                   `json:"description"`
+	Discount            models.Decimal           `json:"discount" binding:"min=0"`
+	DiscountType        string                   `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	Currency            string                   `json:"currency" binding:"required,iso4217"`
+	MaxDiscount         models.Money             `json:"max_discount" binding:"min=0"`
+	MinOrderAmount      models.Money             `json:"min_order_amount" binding:"min=0"`
+	MinItemCount        int                      `json:"min_item_count" binding:"min=0"`
+	MaxUsage            int                      `json:"max_usage" binding:"required,min=1"`
+	MaxUsagePerCustomer int                      `json:"max_usage_per_customer" binding:"min=0"`
+	ValidFrom           time.Time                `json:"valid_from" binding:"required"`
+	ValidUntil          time.Time                `json:"valid_until" binding:"required,gtfield=ValidFrom"`
+	StackingMode        string                   `json:"stacking_mode" binding:"required,oneof=exclusive stackable"`
+	ExclusivityGroup    string                   `json:"exclusivity_group" binding:"max=50"`
+	CampaignID          *uint                    `json:"campaign_id"`
+	Targets             []VoucherTargetRequest   `json:"targets" binding:"dive"`
+	Schedules           []VoucherScheduleRequest `json:"schedules" binding:"dive"`
 }
 
 type VoucherResponse struct {
-	ID          uint                `json:"id"`
-	Code        string              `json:"code"`
-	Name        string              `json:"name"`
-	Description string              `json:"description"`
-	Discount    float64             `json:"discount"`
-	MaxUsage    int                 `json:"max_usage"`
-	UsedCount   int                 `json:"used_count"`
-	ValidFrom   utils.ReadableTime  `json:"valid_from"`
-	ValidUntil  utils.ReadableTime  `json:"valid_until"`
-	IsActive    bool                `json:"is_active"`
-	CreatedAt   utils.ReadableTime  `json:"created_at"`
-	UpdatedAt   utils.ReadableTime  `json:"updated_at"`
+	ID                  uint                      `json:"id"`
+	Code                string                    `json:"code"`
+	Name                string                    `json:"name"`
+	Description         string                    `json:"description"`
+	Discount            models.Decimal            `json:"discount"`
+	DiscountType        string                    `json:"discount_type"`
+	Currency            string                    `json:"currency"`
+	MaxDiscount         models.Money              `json:"max_discount"`
+	MinOrderAmount      models.Money              `json:"min_order_amount"`
+	MinItemCount        int                       `json:"min_item_count"`
+	MaxUsage            int                       `json:"max_usage"`
+	MaxUsagePerCustomer int                       `json:"max_usage_per_customer"`
+	UsedCount           int                       `json:"used_count"`
+	ReservedCount       int                       `json:"reserved_count"`
+	ValidFrom           utils.ReadableTime        `json:"valid_from"`
+	ValidUntil          utils.ReadableTime        `json:"valid_until"`
+	Status              string                    `json:"status"`
+	IsActive            bool                      `json:"is_active"`
+	StackingMode        string                    `json:"stacking_mode"`
+	ExclusivityGroup    string                    `json:"exclusivity_group"`
+	CampaignID          *uint                     `json:"campaign_id"`
+	Targets             []VoucherTargetResponse   `json:"targets"`
+	Schedules           []VoucherScheduleResponse `json:"schedules"`
+	Version             int                       `json:"version"`
+	CreatedAt           utils.ReadableTime        `json:"created_at"`
+	UpdatedAt           utils.ReadableTime        `json:"updated_at"`
+}
+
+// VoucherETag is the entity tag of a voucher representation. The status is
+// part of it because it is derived from the time as well as the stored
+// version, e.g. a scheduled voucher becomes active without a write.
+func VoucherETag(version int, status string) string {
+	return fmt.Sprintf(`"%d-%s"`, version, status)
+}
+
+// Computed statuses place a voucher in its validity period and usage,
+// whatever its lifecycle status. Every voucher has exactly one of them.
+const (
+	ComputedStatusUpcoming  = "upcoming"
+	ComputedStatusRunning   = "running"
+	ComputedStatusExpired   = "expired"
+	ComputedStatusExhausted = "exhausted"
+)
+
+// VoucherFilter selects vouchers for the list, the CSV export and bulk
+// actions, which all bind it from the query string, so the same parameters
+// pick the same vouchers everywhere. Time ranges include the "after" bound
+// and exclude the "before" bound; discount and usage ranges include both.
+type VoucherFilter struct {
+	Search           string          `form:"search"`
+	Codes            []string        `form:"codes" collection_format:"csv" binding:"max=1000"`
+	IsActive         *bool           `form:"is_active"`
+	Status           string          `form:"status" binding:"omitempty,oneof=draft scheduled active paused exhausted expired archived"`
+	ComputedStatus   string          `form:"computed_status" binding:"omitempty,oneof=upcoming running expired exhausted"`
+	DiscountType     string          `form:"discount_type" binding:"omitempty,oneof=percentage fixed"`
+	DiscountMin      *models.Decimal `form:"discount_min" binding:"omitempty,min=0"`
+	DiscountMax      *models.Decimal `form:"discount_max" binding:"omitempty,min=0"`
+	UsageMin         *float64        `form:"usage_min" binding:"omitempty,min=0,max=1"`
+	UsageMax         *float64        `form:"usage_max" binding:"omitempty,min=0,max=1"`
+	ValidFromAfter   time.Time       `form:"valid_from_after"`
+	ValidFromBefore  time.Time       `form:"valid_from_before"`
+	ValidUntilAfter  time.Time       `form:"valid_until_after"`
+	ValidUntilBefore time.Time       `form:"valid_until_before"`
+	CreatedAfter     time.Time       `form:"created_after"`
+	CreatedBefore    time.Time       `form:"created_before"`
+	SKU              string          `form:"sku"`
+	Category         string          `form:"category"`
+	TargetMode       string          `form:"target_mode" binding:"omitempty,oneof=include exclude"`
+	CampaignID       uint            `form:"campaign_id"`
+}
+
+// CodeList returns the codes to match in their normalized form, without
+// empty entries.
+func (f VoucherFilter) CodeList() []string {
+	codes := make([]string, 0, len(f.Codes))
+	for _, code := range f.Codes {
+		if code = models.NormalizeVoucherCode(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// IsEmpty reports whether the filter selects every voucher.
+func (f VoucherFilter) IsEmpty() bool {
+	return f.Search == "" && len(f.CodeList()) == 0 && f.IsActive == nil && f.Status == "" &&
+		f.ComputedStatus == "" && f.DiscountType == "" && f.DiscountMin == nil && f.DiscountMax == nil &&
+		f.UsageMin == nil && f.UsageMax == nil &&
+		f.ValidFromAfter.IsZero() && f.ValidFromBefore.IsZero() &&
+		f.ValidUntilAfter.IsZero() && f.ValidUntilBefore.IsZero() &&
+		f.CreatedAfter.IsZero() && f.CreatedBefore.IsZero() &&
+		f.SKU == "" && f.Category == "" && f.CampaignID == 0
 }
 
 type VoucherListQuery struct {
-	Page     int    `form:"page" binding:"omitempty,min=1"`
-	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
-	Search   string `form:"search"`
-	SortBy   string `form:"sort_by" binding:"omitempty,oneof=id code name discount created_at"`
-	SortOrder string `form:"sort_order" binding:"omitempty,oneof=asc desc"`
-	IsActive *bool  `form:"is_active"`
+	VoucherFilter
+	Page         int    `form:"page" binding:"omitempty,min=1"`
+	PageSize     int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Cursor       string `form:"cursor"`
+	IncludeTotal *bool  `form:"include_total"`
+	SortBy       string `form:"sort_by" binding:"omitempty,oneof=id code name discount created_at"`
+	SortOrder    string `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+}
+
+// Sort returns the column and direction the list is sorted by, with the
+// defaults filled in.
+func (q VoucherListQuery) Sort() (string, string) {
+	sortBy := "created_at"
+	sortOrder := "asc"
+	if q.SortBy != "" {
+		sortBy = q.SortBy
+	}
+	if q.SortOrder != "" {
+		sortOrder = q.SortOrder
+	}
+	return sortBy, sortOrder
 }
 
 type PaginationMeta struct {
@@ -59,9 +241,21 @@ type PaginationMeta struct {
 	TotalItems  int64 `json:"total_items"`
 }
 
+// VoucherPaginationMeta describes a page of the voucher list, found by page
+// number (current_page is set) or by cursor. The totals are only set when
+// they were counted.
+type VoucherPaginationMeta struct {
+	CurrentPage int    `json:"current_page,omitempty"`
+	PageSize    int    `json:"page_size"`
+	TotalPages  *int   `json:"total_pages,omitempty"`
+	TotalItems  *int64 `json:"total_items,omitempty"`
+	HasMore     bool   `json:"has_more"`
+	NextCursor  string `json:"next_cursor,omitempty"`
+}
+
 type VoucherListResponse struct {
-	Data       []VoucherResponse `json:"data"`
-	Pagination PaginationMeta    `json:"pagination"`
+	Data       []VoucherResponse     `json:"data"`
+	Pagination VoucherPaginationMeta `json:"pagination"`
 }
 
 type CSVUploadResponse struct {
@@ -69,3 +263,21 @@ type CSVUploadResponse struct {
 	FailedCount  int      `json:"failed_count"`
 	Errors       []string `json:"errors,omitempty"`
 }
+
+// GenerateCodesRequest describes codes built as prefix + random part + suffix.
+// Charset defaults to uppercase letters and digits without O, 0, I and 1, and
+// Status, the status of the new vouchers, to draft.
+type GenerateCodesRequest struct {
+	Count   int    `json:"count" binding:"required,min=1,max=100000"`
+	Prefix  string `json:"prefix" binding:"omitempty,alphanum,max=20"`
+	Suffix  string `json:"suffix" binding:"omitempty,alphanum,max=20"`
+	Length  int    `json:"length" binding:"omitempty,min=4,max=32"`
+	Charset string `json:"charset" binding:"omitempty,alphanum,max=64"`
+	Status  string `json:"status" binding:"omitempty,oneof=draft active paused"`
+}
+
+type GenerateCodesResponse struct {
+	TemplateID uint     `json:"template_id"`
+	Count      int      `json:"count"`
+	Codes      []string `json:"codes"`
+}
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/services"
+)
+
+// StartIdempotencyKeyPurger deletes expired idempotency keys, with their
+// stored responses, every interval until ctx is cancelled. It runs in its own
+// goroutine.
+func StartIdempotencyKeyPurger(ctx context.Context, idempotencyService services.IdempotencyService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purged, err := idempotencyService.PurgeExpired()
+				if err != nil {
+					log.Printf("Failed to purge expired idempotency keys: %v", err)
+					continue
+				}
+				if purged > 0 {
+					log.Printf("Purged %d expired idempotency keys", purged)
+				}
+			}
+		}
+	}()
+}
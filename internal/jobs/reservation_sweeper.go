@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/services"
+)
+
+// StartReservationSweeper releases expired voucher reservations every interval
+// until ctx is cancelled. It runs in its own goroutine.
+func StartReservationSweeper(ctx context.Context, redemptionService services.RedemptionService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				released, err := redemptionService.ReleaseExpiredReservations()
+				if err != nil {
+					log.Printf("Failed to release expired reservations: %v", err)
+					continue
+				}
+				if released > 0 {
+					log.Printf("Released %d expired voucher reservations", released)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/services"
+)
+
+// StartTrashPurger permanently deletes vouchers past the trash retention
+// period every interval until ctx is cancelled. It runs in its own goroutine.
+func StartTrashPurger(ctx context.Context, voucherService services.VoucherService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purged, err := voucherService.PurgeExpiredTrash()
+				if err != nil {
+					log.Printf("Failed to purge expired trash: %v", err)
+					continue
+				}
+				if purged > 0 {
+					log.Printf("Purged %d vouchers past the trash retention period", purged)
+				}
+			}
+		}
+	}()
+}
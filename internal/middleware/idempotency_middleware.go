@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rifqi142/indico-be/internal/services"
+	"github.com/rifqi142/indico-be/internal/utils"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// replayedHeaders are the response headers stored with a key and sent again
+// when its response is replayed.
+var replayedHeaders = []string{"ETag", "Location"}
+
+type responseCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseCaptureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware stores the first response for each Idempotency-Key and
+// replays it for retries with the same payload. Requests without the header are
+// passed through unchanged.
+func IdempotencyMiddleware(idempotencyService services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > 255 {
+			utils.BadRequestResponse(c, "Idempotency-Key must be at most 255 characters", nil)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			utils.BadRequestResponse(c, "Failed to read request body", err.Error())
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// The hash covers the request path as well as the body, so a key reused
+		// on another resource, e.g. to confirm a different reservation, is a
+		// mismatch rather than a replay of the first response
+		hash := sha256.Sum256(append([]byte(c.Request.URL.Path+"\n"), body...))
+		scope := c.GetString("username") + " " + c.Request.Method + " " + c.FullPath()
+
+		record, err := idempotencyService.Begin(scope, key, hex.EncodeToString(hash[:]))
+		if err != nil {
+			if errors.Is(err, services.ErrIdempotencyKeyMismatch) || errors.Is(err, services.ErrIdempotencyKeyInProgress) {
+				utils.ConflictResponse(c, err.Error(), nil)
+			} else {
+				utils.InternalServerErrorResponse(c, "Failed to process idempotency key", err.Error())
+			}
+			c.Abort()
+			return
+		}
+
+		// Replay the stored response of the original request
+		if record.IsCompleted() {
+			for name, value := range record.Headers() {
+				c.Header(name, value)
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, "application/json; charset=utf-8", record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		release := func() {
+			if err := idempotencyService.Release(record); err != nil {
+				log.Printf("Failed to release idempotency key %s: %v", key, err)
+			}
+		}
+
+		// A panicking handler never completes the key, so free it for a retry
+		// and leave the panic to the recovery middleware
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				release()
+				panic(recovered)
+			}
+		}()
+
+		writer := &responseCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		headers := map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := idempotencyService.Complete(record, writer.Status(), headers, writer.body.Bytes()); err != nil {
+			log.Printf("Failed to store idempotent response for key %s: %v", key, err)
+		}
+	}
+}
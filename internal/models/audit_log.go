@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionCreate   = "create"
+	AuditActionUpdate   = "update"
+	AuditActionDelete   = "delete"
+	AuditActionRestore  = "restore"
+	AuditActionPurge    = "purge"
+	AuditActionImport   = "import"
+	AuditActionGenerate = "generate"
+	AuditActionRedeem   = "redeem"
+	AuditActionReserve  = "reserve"
+	AuditActionConfirm  = "confirm"
+	AuditActionRelease  = "release"
+	AuditActionExpire   = "expire"
+	AuditActionReverse  = "reverse"
+)
+
+var ErrAuditLogImmutable = errors.New("audit log entries cannot be changed or deleted")
+
+// AuditLog records one mutation of a voucher: who made it, from which request
+// and which fields changed. Lifecycle transitions use the transition name
+// (publish, pause, resume, archive) as their action. Changes is a JSON object
+// of field name to {"from": ..., "to": ...}.
+type AuditLog struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	VoucherID       uint      `gorm:"not null;index" json:"voucher_id"`
+	VoucherCode     string    `gorm:"not null;size:50" json:"voucher_code"`
+	RedemptionID    *uint     `gorm:"index" json:"redemption_id,omitempty"`
+	ChangeRequestID *uint     `gorm:"index" json:"change_request_id,omitempty"`
+	Action          string    `gorm:"not null;size:30;index" json:"action"`
+	Actor           string    `gorm:"not null;size:100;index" json:"actor"`
+	RequestID       string    `gorm:"size:100;index" json:"request_id"`
+	Changes         string    `gorm:"type:text;not null;default:'{}'" json:"-"`
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "voucher_audit_logs"
+}
+
+// BeforeUpdate keeps entries append-only.
+func (*AuditLog) BeforeUpdate(*gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// BeforeDelete keeps entries append-only.
+func (*AuditLog) BeforeDelete(*gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CampaignStatusActive = "active"
+	CampaignStatusPaused = "paused"
+)
+
+// Campaign groups vouchers under one owner, date range and discount budget.
+// Budget is the total discount the campaign's vouchers may give; zero means
+// unlimited. BudgetReserved holds the discounts of open reservations.
+type Campaign struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	Name           string         `gorm:"not null;size:255" json:"name"`
+	Description    string         `gorm:"type:text" json:"description"`
+	Owner          string         `gorm:"not null;size:100;index" json:"owner"`
+	StartsAt       time.Time      `gorm:"not null" json:"starts_at"`
+	EndsAt         time.Time      `gorm:"not null" json:"ends_at"`
+	Currency       string         `gorm:"not null;size:3;default:IDR" json:"currency"`
+	Budget         Money          `gorm:"not null;default:0" json:"budget"`
+	BudgetUsed     Money          `gorm:"not null;default:0" json:"budget_used"`
+	BudgetReserved Money          `gorm:"not null;default:0" json:"budget_reserved"`
+	Status         string         `gorm:"not null;size:20;default:active;index" json:"status"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (Campaign) TableName() string {
+	return "campaigns"
+}
+
+func (c *Campaign) IsActive() bool {
+	return c.Status == CampaignStatusActive
+}
+
+// IsRunning reports whether the campaign is active and inside its date range.
+func (c *Campaign) IsRunning(at time.Time) bool {
+	return c.IsActive() && !at.Before(c.StartsAt) && at.Before(c.EndsAt)
+}
+
+func (c *Campaign) HasBudget() bool {
+	return c.Budget > 0
+}
+
+// RemainingBudget is what is left once redeemed and reserved discounts are
+// both taken into account.
+func (c *Campaign) RemainingBudget() Money {
+	return c.Budget - c.BudgetUsed - c.BudgetReserved
+}
+
+// CanCover reports whether the budget still allows the given discount.
+func (c *Campaign) CanCover(discount Money) bool {
+	return !c.HasBudget() || discount <= c.RemainingBudget()
+}
+
+func (c *Campaign) Spend(amount Money) {
+	c.BudgetUsed += amount
+}
+
+func (c *Campaign) Refund(amount Money) {
+	c.BudgetUsed = max(c.BudgetUsed-amount, 0)
+}
+
+func (c *Campaign) Hold(amount Money) {
+	c.BudgetReserved += amount
+}
+
+func (c *Campaign) ReleaseHold(amount Money) {
+	c.BudgetReserved = max(c.BudgetReserved-amount, 0)
+}
+
+func (c *Campaign) ConfirmHold(amount Money) {
+	c.ReleaseHold(amount)
+	c.Spend(amount)
+}
@@ -0,0 +1,20 @@
+package models
+
+// Cart describes the order a voucher is checked against. It is not stored.
+type Cart struct {
+	Amount    Money
+	Currency  string
+	ItemCount int
+	Lines     []CartLine
+}
+
+type CartLine struct {
+	SKU       string
+	Category  string
+	Quantity  int
+	UnitPrice Money
+}
+
+func (l CartLine) Subtotal() Money {
+	return l.UnitPrice.Times(l.Quantity)
+}
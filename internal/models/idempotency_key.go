@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type IdempotencyKey struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Scope           string     `gorm:"not null;size:255;uniqueIndex:idx_idempotency_keys_scope_key" json:"scope"`
+	Key             string     `gorm:"not null;size:255;uniqueIndex:idx_idempotency_keys_scope_key" json:"key"`
+	RequestHash     string     `gorm:"not null;size:64" json:"request_hash"`
+	StatusCode      int        `gorm:"default:0" json:"status_code"`
+	ResponseBody    []byte     `gorm:"type:bytea" json:"-"`
+	ResponseHeaders string     `gorm:"type:text" json:"-"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt       time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.CompletedAt != nil
+}
+
+func (k *IdempotencyKey) IsExpired() bool {
+	return time.Now().After(k.ExpiresAt)
+}
+
+// Headers decodes ResponseHeaders, the JSON object of response headers sent
+// again on replays.
+func (k *IdempotencyKey) Headers() map[string]string {
+	headers := map[string]string{}
+	if k.ResponseHeaders != "" {
+		_ = json.Unmarshal([]byte(k.ResponseHeaders), &headers)
+	}
+	return headers
+}
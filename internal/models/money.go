@@ -0,0 +1,196 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when a voucher or cart does not name one.
+const DefaultCurrency = "IDR"
+
+// SupportedCurrencies lists the ISO 4217 codes a voucher may use. All of them
+// have two-digit minor units, which is what Money assumes.
+var SupportedCurrencies = map[string]bool{
+	"IDR": true,
+	"USD": true,
+	"SGD": true,
+	"MYR": true,
+	"EUR": true,
+}
+
+func IsSupportedCurrency(code string) bool {
+	return SupportedCurrencies[code]
+}
+
+// decimalScale is the number of hundredths in one whole unit. Money and
+// Decimal both keep exactly two fractional digits.
+const decimalScale = 100
+
+var ErrTooManyDecimals = errors.New("value must have at most 2 decimal places")
+
+// Money is an amount in minor units of its currency (sen for IDR), so
+// Rp50.000 is Money(5000000). It is stored as BIGINT and written in JSON and
+// CSV as a decimal with two fractional digits.
+//
+// Rounding rules:
+//   - input with more than two decimal places is rejected, never rounded;
+//   - percentage discounts are rounded down to the minor unit (see Percent);
+//   - sums and caps are plain integer arithmetic, so they never drift.
+type Money int64
+
+// Decimal is a plain number with two fractional digits, stored as a count of
+// hundredths. Voucher discounts use it: 12.5% is Decimal(1250) and a fixed
+// Rp10.000 discount is Decimal(1000000), the same value as its Money amount.
+type Decimal int64
+
+func ParseMoney(value string) (Money, error) {
+	n, err := parseHundredths(value)
+	return Money(n), err
+}
+
+func ParseDecimal(value string) (Decimal, error) {
+	n, err := parseHundredths(value)
+	return Decimal(n), err
+}
+
+func (m Money) String() string {
+	return formatHundredths(int64(m))
+}
+
+func (d Decimal) String() string {
+	return formatHundredths(int64(d))
+}
+
+// Percent applies d, read as a percentage, to the amount. The result is
+// rounded down to the minor unit so a discount never exceeds its rate.
+func (m Money) Percent(d Decimal) Money {
+	if m <= 0 || d <= 0 {
+		return 0
+	}
+	hi, lo := bits.Mul64(uint64(m), uint64(d))
+	if hi >= 100*decimalScale {
+		return m
+	}
+	quotient, _ := bits.Div64(hi, lo, 100*decimalScale)
+	return Money(quotient)
+}
+
+// Times multiplies the amount by a whole quantity, e.g. a unit price by the
+// number of items.
+func (m Money) Times(quantity int) Money {
+	return m * Money(quantity)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	n, err := unmarshalHundredths(data)
+	if err != nil {
+		return err
+	}
+	*m = Money(n)
+	return nil
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	n, err := unmarshalHundredths(data)
+	if err != nil {
+		return err
+	}
+	*d = Decimal(n)
+	return nil
+}
+
+// UnmarshalParam binds a query parameter as written, e.g. discount_min=12.5.
+func (d *Decimal) UnmarshalParam(param string) error {
+	n, err := parseHundredths(param)
+	if err != nil {
+		return err
+	}
+	*d = Decimal(n)
+	return nil
+}
+
+// unmarshalHundredths accepts a JSON number or a numeric string and parses
+// it from its text, so the value never passes through a float.
+func unmarshalHundredths(data []byte) (int64, error) {
+	value := strings.TrimSpace(string(data))
+	if value == "null" {
+		return 0, nil
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	return parseHundredths(value)
+}
+
+func parseHundredths(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	negative := false
+	switch value[0] {
+	case '-':
+		negative = true
+		value = value[1:]
+	case '+':
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if len(fraction) > 2 {
+		if strings.TrimRight(fraction[2:], "0") != "" {
+			return 0, ErrTooManyDecimals
+		}
+		fraction = fraction[:2]
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("invalid decimal %q", value)
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > (math.MaxInt64-decimalScale)/decimalScale {
+		return 0, fmt.Errorf("decimal %q is out of range", value)
+	}
+	hundredths, _ := strconv.ParseInt(fraction, 10, 64)
+
+	n := units*decimalScale + hundredths
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+func formatHundredths(n int64) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/decimalScale, n%decimalScale)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	RedemptionStatusReserved = "reserved"
+	RedemptionStatusRedeemed = "redeemed"
+	RedemptionStatusReversed = "reversed"
+	RedemptionStatusReleased = "released"
+	RedemptionStatusExpired  = "expired"
+)
+
+type Redemption struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	VoucherID      uint       `gorm:"not null;index;index:idx_voucher_redemptions_voucher_customer,priority:1" json:"voucher_id"`
+	VoucherCode    string     `gorm:"not null;size:50" json:"voucher_code"`
+	CampaignID     *uint      `gorm:"index" json:"campaign_id,omitempty"`
+	CustomerRef    string     `gorm:"not null;size:100;index;index:idx_voucher_redemptions_voucher_customer,priority:2" json:"customer_ref"`
+	OrderRef       string     `gorm:"not null;size:100;index" json:"order_ref"`
+	OrderAmount    Money      `gorm:"not null;default:0" json:"order_amount"`
+	DiscountAmount Money      `gorm:"not null;default:0" json:"discount_amount"`
+	Currency       string     `gorm:"not null;size:3;default:IDR" json:"currency"`
+	Status         string     `gorm:"not null;size:20;default:redeemed;index" json:"status"`
+	ReservedAt     *time.Time `json:"reserved_at,omitempty"`
+	ExpiresAt      *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	RedeemedAt     *time.Time `json:"redeemed_at,omitempty"`
+	ReleasedAt     *time.Time `json:"released_at,omitempty"`
+	ReversedAt     *time.Time `json:"reversed_at,omitempty"`
+	ReversalReason string     `gorm:"type:text" json:"reversal_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Redemption) TableName() string {
+	return "voucher_redemptions"
+}
+
+func (r *Redemption) IsRedeemed() bool {
+	return r.Status == RedemptionStatusRedeemed
+}
+
+func (r *Redemption) IsReserved() bool {
+	return r.Status == RedemptionStatusReserved
+}
+
+func (r *Redemption) IsReversed() bool {
+	return r.Status == RedemptionStatusReversed
+}
+
+// IsReservationExpired reports whether the hold ran out before being confirmed.
+func (r *Redemption) IsReservationExpired(at time.Time) bool {
+	return r.IsReserved() && r.ExpiresAt != nil && !at.Before(*r.ExpiresAt)
+}
+
+func (r *Redemption) Confirm() {
+	now := time.Now()
+	r.Status = RedemptionStatusRedeemed
+	r.RedeemedAt = &now
+}
+
+// Release ends a reservation without using it, either on request
+// (RedemptionStatusReleased) or because it timed out (RedemptionStatusExpired).
+func (r *Redemption) Release(status string) {
+	now := time.Now()
+	r.Status = status
+	r.ReleasedAt = &now
+}
+
+func (r *Redemption) Reverse(reason string) {
+	now := time.Now()
+	r.Status = RedemptionStatusReversed
+	r.ReversedAt = &now
+	r.ReversalReason = reason
+}
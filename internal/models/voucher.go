@@ -1,43 +1,297 @@
 package models
 
 import (
+	"strings"
 	"time"
+	"unicode"
 
 	"gorm.io/gorm"
 )
 
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+const (
+	StackingModeExclusive = "exclusive"
+	StackingModeStackable = "stackable"
+)
+
+type ValidationReason string
+
+const (
+	ReasonNotFound   ValidationReason = "not_found"
+	ReasonInactive   ValidationReason = "inactive"
+	ReasonNotStarted ValidationReason = "not_started"
+	ReasonExpired    ValidationReason = "expired"
+	ReasonExhausted  ValidationReason = "exhausted"
+
+	ReasonOutsideSchedule ValidationReason = "outside_schedule"
+
+	ReasonCampaignInactive ValidationReason = "campaign_inactive"
+	ReasonCampaignBudget   ValidationReason = "campaign_budget_exhausted"
+
+	ReasonCurrencyMismatch ValidationReason = "currency_mismatch"
+	ReasonMinOrderAmount   ValidationReason = "min_order_amount_not_met"
+	ReasonMinItemCount     ValidationReason = "min_item_count_not_met"
+	ReasonCustomerLimit    ValidationReason = "customer_limit_reached"
+	ReasonNoEligibleItems  ValidationReason = "no_eligible_items"
+
+	ReasonNotCombinable ValidationReason = "not_combinable"
+	ReasonGroupConflict ValidationReason = "exclusivity_group_conflict"
+	ReasonDuplicate     ValidationReason = "duplicate"
+)
+
+var validationReasonMessages = map[ValidationReason]string{
+	ReasonNotFound:   "Voucher code not found",
+	ReasonInactive:   "Voucher is not active",
+	ReasonNotStarted: "Voucher is not valid yet",
+	ReasonExpired:    "Voucher has expired",
+	ReasonExhausted:  "Voucher usage limit has been reached",
+
+	ReasonOutsideSchedule: "Voucher cannot be used at this time",
+
+	ReasonCampaignInactive: "Voucher campaign is paused or not running",
+	ReasonCampaignBudget:   "Voucher campaign budget has been used up",
+
+	ReasonCurrencyMismatch: "Order currency does not match the voucher currency",
+	ReasonMinOrderAmount:   "Order amount is below the voucher minimum purchase",
+	ReasonMinItemCount:     "Order does not contain enough items for this voucher",
+	ReasonCustomerLimit:    "Customer has reached the usage limit for this voucher",
+	ReasonNoEligibleItems:  "Cart does not contain any products this voucher applies to",
+
+	ReasonNotCombinable: "Voucher cannot be combined with the other vouchers",
+	ReasonGroupConflict: "Another voucher from the same group gives a larger discount",
+	ReasonDuplicate:     "Voucher code was entered more than once",
+}
+
+func (r ValidationReason) Message() string {
+	return validationReasonMessages[r]
+}
+
 type Voucher struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Code        string         `gorm:"uniqueIndex;not null;size:50" json:"code"`
-	Name        string         `gorm:"not null;size:255" json:"name"`
-	Description string         `gorm:"type:text" json:"description"`
-	Discount    float64        `gorm:"not null" json:"discount"`
-	MaxUsage    int            `gorm:"not null;default:1" json:"max_usage"`
-	UsedCount   int            `gorm:"default:0" json:"used_count"`
-	ValidFrom   time.Time      `gorm:"not null" json:"valid_from"`
-	ValidUntil  time.Time      `gorm:"not null" json:"valid_until"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID                  uint           `gorm:"primaryKey;index:idx_vouchers_created_at_id,priority:2" json:"id"`
+	Code                string         `gorm:"not null;size:50;uniqueIndex:idx_vouchers_code_live,where:deleted_at IS NULL;check:chk_vouchers_code_normalized,code = UPPER(code) AND code !~ '[[:space:]-]'" json:"code"`
+	Name                string         `gorm:"not null;size:255" json:"name"`
+	Description         string         `gorm:"type:text" json:"description"`
+	Discount            Decimal        `gorm:"not null" json:"discount"`
+	DiscountType        string         `gorm:"not null;size:20;default:percentage" json:"discount_type"`
+	Currency            string         `gorm:"not null;size:3;default:IDR" json:"currency"`
+	MaxDiscount         Money          `gorm:"not null;default:0" json:"max_discount"`
+	MinOrderAmount      Money          `gorm:"not null;default:0" json:"min_order_amount"`
+	MinItemCount        int            `gorm:"not null;default:0" json:"min_item_count"`
+	MaxUsage            int            `gorm:"not null;default:1" json:"max_usage"`
+	MaxUsagePerCustomer int            `gorm:"not null;default:0" json:"max_usage_per_customer"`
+	UsedCount           int            `gorm:"default:0" json:"used_count"`
+	ReservedCount       int            `gorm:"not null;default:0" json:"reserved_count"`
+	ValidFrom           time.Time      `gorm:"not null" json:"valid_from"`
+	ValidUntil          time.Time      `gorm:"not null" json:"valid_until"`
+	Status              string         `gorm:"not null;size:20;default:active;index" json:"status"`
+	StackingMode        string         `gorm:"not null;size:20;default:exclusive" json:"stacking_mode"`
+	ExclusivityGroup    string         `gorm:"size:50;index" json:"exclusivity_group"`
+	CampaignID          *uint          `gorm:"index" json:"campaign_id"`
+	PausedByCampaign    bool           `gorm:"not null;default:false" json:"-"`
+	Version             int            `gorm:"not null;default:1" json:"version"`
+	CreatedAt           time.Time      `gorm:"index:idx_vouchers_created_at_id,priority:1" json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	Targets   []VoucherTarget   `gorm:"foreignKey:VoucherID" json:"targets,omitempty"`
+	Schedules []VoucherSchedule `gorm:"foreignKey:VoucherID" json:"schedules,omitempty"`
+	Campaign  *Campaign         `gorm:"foreignKey:CampaignID" json:"campaign,omitempty"`
 }
 
 func (Voucher) TableName() string {
 	return "vouchers"
 }
 
+// NormalizeVoucherCode puts a code in the form it is stored and looked up in:
+// uppercase, without whitespace or dashes. "welcome-2025", " Welcome 2025 "
+// and "WELCOME2025" are all the same code.
+func NormalizeVoucherCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.Is(unicode.Pd, r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, code)
+}
+
 func (v *Voucher) IsValid() bool {
-	now := time.Now()
-	return v.IsActive &&
-		v.UsedCount < v.MaxUsage &&
-		now.After(v.ValidFrom) &&
-		now.Before(v.ValidUntil)
+	return v.CheckValidity(time.Now()) == ""
+}
+
+// CheckValidity returns why the voucher cannot be used at the given time,
+// or an empty reason when it can.
+func (v *Voucher) CheckValidity(at time.Time) ValidationReason {
+	switch {
+	case !v.IsEnabled():
+		return ReasonInactive
+	case !at.After(v.ValidFrom):
+		return ReasonNotStarted
+	case !at.Before(v.ValidUntil):
+		return ReasonExpired
+	case !v.InSchedule(at):
+		return ReasonOutsideSchedule
+	case v.Campaign != nil && !v.Campaign.IsRunning(at):
+		return ReasonCampaignInactive
+	case v.RemainingUsage() <= 0:
+		return ReasonExhausted
+	}
+	return ""
+}
+
+// InSchedule reports whether the time falls inside one of the voucher's
+// recurring windows. Vouchers without schedules are usable at any time.
+func (v *Voucher) InSchedule(at time.Time) bool {
+	if len(v.Schedules) == 0 {
+		return true
+	}
+	for _, schedule := range v.Schedules {
+		if schedule.Contains(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemainingUsage is the number of uses left once confirmed usage and
+// unconfirmed reservations are both taken into account.
+func (v *Voucher) RemainingUsage() int {
+	return v.MaxUsage - v.UsedCount - v.ReservedCount
+}
+
+// CheckEligibility returns which order-level condition the cart fails,
+// or an empty reason when all of them are met.
+func (v *Voucher) CheckEligibility(cart Cart) ValidationReason {
+	switch {
+	case cart.Currency != "" && cart.Currency != v.Currency:
+		return ReasonCurrencyMismatch
+	case cart.Amount < v.MinOrderAmount:
+		return ReasonMinOrderAmount
+	case cart.ItemCount < v.MinItemCount:
+		return ReasonMinItemCount
+	case len(v.Targets) > 0 && v.ApplicableAmount(cart) <= 0:
+		return ReasonNoEligibleItems
+	}
+	return ""
+}
+
+// AppliesTo reports whether the voucher discounts the cart line. Exclusions
+// win over inclusions, and a voucher with inclusions only applies to the
+// lines they name.
+func (v *Voucher) AppliesTo(line CartLine) bool {
+	hasInclude := false
+	included := false
+	for _, target := range v.Targets {
+		if target.IsExclude() {
+			if target.Matches(line) {
+				return false
+			}
+			continue
+		}
+		hasInclude = true
+		if target.Matches(line) {
+			included = true
+		}
+	}
+	return !hasInclude || included
+}
+
+// ApplicableAmount is the part of the cart the voucher discounts. Untargeted
+// vouchers apply to the whole cart amount.
+func (v *Voucher) ApplicableAmount(cart Cart) Money {
+	if len(v.Targets) == 0 {
+		return cart.Amount
+	}
+
+	var amount Money
+	for _, line := range cart.Lines {
+		if v.AppliesTo(line) {
+			amount += line.Subtotal()
+		}
+	}
+	return amount
+}
+
+// CheckCustomerUsage reports whether a customer who already used the voucher
+// the given number of times may use it again. Zero MaxUsagePerCustomer means no limit.
+func (v *Voucher) CheckCustomerUsage(used int64) ValidationReason {
+	if v.MaxUsagePerCustomer > 0 && used >= int64(v.MaxUsagePerCustomer) {
+		return ReasonCustomerLimit
+	}
+	return ""
+}
+
+// CheckOrder combines CheckValidity and CheckEligibility for a cart checked out at the given time.
+func (v *Voucher) CheckOrder(at time.Time, cart Cart) ValidationReason {
+	if reason := v.CheckValidity(at); reason != "" {
+		return reason
+	}
+	return v.CheckEligibility(cart)
 }
 
 func (v *Voucher) CanBeUsed() bool {
-	return v.IsValid() && v.UsedCount < v.MaxUsage
+	return v.IsValid() && v.RemainingUsage() > 0
 }
 
 func (v *Voucher) IncrementUsage() {
 	v.UsedCount++
 }
+
+func (v *Voucher) DecrementUsage() {
+	if v.UsedCount > 0 {
+		v.UsedCount--
+	}
+}
+
+func (v *Voucher) Reserve() {
+	v.ReservedCount++
+}
+
+func (v *Voucher) ReleaseReservation() {
+	if v.ReservedCount > 0 {
+		v.ReservedCount--
+	}
+}
+
+func (v *Voucher) ConfirmReservation() {
+	v.ReleaseReservation()
+	v.IncrementUsage()
+}
+
+// IsStackable reports whether the voucher may be combined with other
+// stackable vouchers in one order.
+func (v *Voucher) IsStackable() bool {
+	return v.StackingMode == StackingModeStackable
+}
+
+func (v *Voucher) IsFixedDiscount() bool {
+	return v.DiscountType == DiscountTypeFixed
+}
+
+// DiscountFor returns the discount amount this voucher gives on the given order amount.
+// Percentage discounts are rounded down to the minor unit and limited by MaxDiscount
+// when it is set, and the discount never exceeds the order amount itself.
+func (v *Voucher) DiscountFor(amount Money) Money {
+	var discount Money
+	if v.IsFixedDiscount() {
+		discount = Money(v.Discount)
+	} else {
+		discount = amount.Percent(v.Discount)
+		if v.MaxDiscount > 0 && discount > v.MaxDiscount {
+			discount = v.MaxDiscount
+		}
+	}
+
+	if discount > amount {
+		return amount
+	}
+	return discount
+}
+
+// DiscountForCart returns the discount on the matching lines of the cart.
+func (v *Voucher) DiscountForCart(cart Cart) Money {
+	return v.DiscountFor(v.ApplicableAmount(cart))
+}
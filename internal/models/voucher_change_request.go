@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	ChangeActionCreate = "create"
+	ChangeActionUpdate = "update"
+	ChangeActionPatch  = "patch"
+)
+
+const (
+	ChangeStatusPending  = "pending"
+	ChangeStatusApproved = "approved"
+	ChangeStatusRejected = "rejected"
+)
+
+// VoucherChangeRequest holds a voucher create, update or patch that crossed an
+// approval threshold. Payload is the original request body as JSON, applied
+// only once a user other than RequestedBy approves it.
+type VoucherChangeRequest struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	VoucherID   *uint      `gorm:"index" json:"voucher_id,omitempty"`
+	Action      string     `gorm:"not null;size:20" json:"action"`
+	Payload     string     `gorm:"type:text;not null" json:"-"`
+	Reasons     string     `gorm:"type:text" json:"-"`
+	Status      string     `gorm:"not null;size:20;default:pending;index" json:"status"`
+	RequestedBy string     `gorm:"not null;size:100;index" json:"requested_by"`
+	ReviewedBy  string     `gorm:"size:100" json:"reviewed_by,omitempty"`
+	ReviewNote  string     `gorm:"type:text" json:"review_note,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (VoucherChangeRequest) TableName() string {
+	return "voucher_change_requests"
+}
+
+func (c *VoucherChangeRequest) IsPending() bool {
+	return c.Status == ChangeStatusPending
+}
+
+// ReasonList splits the newline separated thresholds the change crossed.
+func (c *VoucherChangeRequest) ReasonList() []string {
+	if c.Reasons == "" {
+		return []string{}
+	}
+	return strings.Split(c.Reasons, "\n")
+}
+
+func (c *VoucherChangeRequest) Approve(reviewer, note string, at time.Time) {
+	c.review(ChangeStatusApproved, reviewer, note, at)
+}
+
+func (c *VoucherChangeRequest) Reject(reviewer, note string, at time.Time) {
+	c.review(ChangeStatusRejected, reviewer, note, at)
+}
+
+func (c *VoucherChangeRequest) review(status, reviewer, note string, at time.Time) {
+	c.Status = status
+	c.ReviewedBy = reviewer
+	c.ReviewNote = note
+	c.ReviewedAt = &at
+}
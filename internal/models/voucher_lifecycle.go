@@ -0,0 +1,127 @@
+package models
+
+import "time"
+
+// Stored voucher statuses. These are the states an operator sets; the state
+// reported to clients is derived from them by State.
+const (
+	VoucherStatusDraft    = "draft"
+	VoucherStatusActive   = "active"
+	VoucherStatusPaused   = "paused"
+	VoucherStatusArchived = "archived"
+)
+
+// VoucherState is the lifecycle state of a voucher at a point in time.
+// Scheduled, expired and exhausted are never stored: they follow from an
+// active or paused voucher's validity period and usage.
+type VoucherState string
+
+const (
+	StateDraft     VoucherState = "draft"
+	StateScheduled VoucherState = "scheduled"
+	StateActive    VoucherState = "active"
+	StatePaused    VoucherState = "paused"
+	StateExhausted VoucherState = "exhausted"
+	StateExpired   VoucherState = "expired"
+	StateArchived  VoucherState = "archived"
+)
+
+var VoucherStates = []VoucherState{
+	StateDraft, StateScheduled, StateActive, StatePaused, StateExhausted, StateExpired, StateArchived,
+}
+
+type VoucherTransition string
+
+const (
+	TransitionPublish VoucherTransition = "publish"
+	TransitionPause   VoucherTransition = "pause"
+	TransitionResume  VoucherTransition = "resume"
+	TransitionArchive VoucherTransition = "archive"
+)
+
+type transitionRule struct {
+	from []VoucherState
+	to   string
+}
+
+// voucherTransitions lists which states each transition may start from.
+// Expired vouchers can only be archived, and archived ones are final.
+var voucherTransitions = map[VoucherTransition]transitionRule{
+	TransitionPublish: {
+		from: []VoucherState{StateDraft},
+		to:   VoucherStatusActive,
+	},
+	TransitionPause: {
+		from: []VoucherState{StateScheduled, StateActive, StateExhausted},
+		to:   VoucherStatusPaused,
+	},
+	TransitionResume: {
+		from: []VoucherState{StatePaused},
+		to:   VoucherStatusActive,
+	},
+	TransitionArchive: {
+		from: []VoucherState{StateDraft, StateScheduled, StateActive, StatePaused, StateExhausted, StateExpired},
+		to:   VoucherStatusArchived,
+	},
+}
+
+func IsVoucherTransition(t VoucherTransition) bool {
+	_, ok := voucherTransitions[t]
+	return ok
+}
+
+// State derives the lifecycle state at the given time. Archived and draft
+// win over everything, an ended validity period wins over paused, and a
+// used-up voucher is only exhausted once it has started.
+func (v *Voucher) State(at time.Time) VoucherState {
+	switch v.Status {
+	case VoucherStatusArchived:
+		return StateArchived
+	case VoucherStatusDraft:
+		return StateDraft
+	}
+
+	switch {
+	case !at.Before(v.ValidUntil):
+		return StateExpired
+	case v.Status == VoucherStatusPaused:
+		return StatePaused
+	case !at.After(v.ValidFrom):
+		return StateScheduled
+	case v.RemainingUsage() <= 0:
+		return StateExhausted
+	}
+	return StateActive
+}
+
+// IsEnabled reports whether the operator has the voucher switched on, i.e.
+// it is neither a draft, paused nor archived.
+func (v *Voucher) IsEnabled() bool {
+	return v.Status == VoucherStatusActive
+}
+
+// CanTransition reports whether the transition is allowed from the voucher's
+// state at the given time.
+func (v *Voucher) CanTransition(t VoucherTransition, at time.Time) bool {
+	rule, ok := voucherTransitions[t]
+	if !ok {
+		return false
+	}
+	state := v.State(at)
+	for _, from := range rule.from {
+		if from == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves the voucher to the transition's target status, reporting
+// false and leaving it unchanged when the transition is not allowed.
+func (v *Voucher) Transition(t VoucherTransition, at time.Time) bool {
+	if !v.CanTransition(t, at) {
+		return false
+	}
+	v.Status = voucherTransitions[t].to
+	return true
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ScheduleTimezone is the zone schedule days and times are written in.
+const ScheduleTimezone = "Asia/Jakarta"
+
+// ScheduleLocation is Asia/Jakarta, falling back to a fixed UTC+7 zone when
+// the host has no tz database. WIB has no daylight saving so both agree.
+var ScheduleLocation = loadScheduleLocation()
+
+func loadScheduleLocation() *time.Location {
+	loc, err := time.LoadLocation(ScheduleTimezone)
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
+
+// ScheduleDays maps the day names used in schedules to weekdays.
+var ScheduleDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+const scheduleClockLayout = "15:04"
+
+// VoucherSchedule is a recurring window, in WIB, inside which a voucher can be
+// used. Empty Days means every day and empty StartTime/EndTime means the whole
+// day. A window whose EndTime is not after StartTime runs past midnight into
+// the next day.
+type VoucherSchedule struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	VoucherID uint      `gorm:"not null;index" json:"voucher_id"`
+	Days      string    `gorm:"size:50" json:"days"`
+	StartTime string    `gorm:"size:5" json:"start_time"`
+	EndTime   string    `gorm:"size:5" json:"end_time"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (VoucherSchedule) TableName() string {
+	return "voucher_schedules"
+}
+
+// DayList returns the schedule days, e.g. ["mon", "fri"].
+func (s *VoucherSchedule) DayList() []string {
+	if s.Days == "" {
+		return nil
+	}
+	return strings.Split(s.Days, ",")
+}
+
+func (s *VoucherSchedule) onDay(day time.Weekday) bool {
+	if s.Days == "" {
+		return true
+	}
+	for _, name := range s.DayList() {
+		if weekday, ok := ScheduleDays[name]; ok && weekday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// window returns the start and end of the schedule in minutes after midnight.
+func (s *VoucherSchedule) window() (int, int) {
+	start, end := 0, 24*60
+	if minutes, ok := ParseScheduleClock(s.StartTime); ok {
+		start = minutes
+	}
+	if minutes, ok := ParseScheduleClock(s.EndTime); ok {
+		end = minutes
+	}
+	return start, end
+}
+
+// Contains reports whether the given instant falls inside the schedule,
+// evaluated on the WIB wall clock.
+func (s *VoucherSchedule) Contains(at time.Time) bool {
+	local := at.In(ScheduleLocation)
+	minute := local.Hour()*60 + local.Minute()
+	start, end := s.window()
+
+	if start < end {
+		return minute >= start && minute < end && s.onDay(local.Weekday())
+	}
+	if minute >= start {
+		return s.onDay(local.Weekday())
+	}
+	if minute < end {
+		return s.onDay((local.Weekday() + 6) % 7)
+	}
+	return false
+}
+
+// ParseScheduleClock converts an "HH:MM" time to minutes after midnight.
+func ParseScheduleClock(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+	t, err := time.Parse(scheduleClockLayout, value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	TargetTypeSKU      = "sku"
+	TargetTypeCategory = "category"
+
+	TargetModeInclude = "include"
+	TargetModeExclude = "exclude"
+)
+
+// VoucherTarget restricts a voucher to, or excludes it from, a product SKU or category.
+type VoucherTarget struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	VoucherID  uint      `gorm:"not null;index" json:"voucher_id"`
+	TargetType string    `gorm:"not null;size:20;index:idx_voucher_targets_type_value,priority:1" json:"target_type"`
+	Value      string    `gorm:"not null;size:100;index:idx_voucher_targets_type_value,priority:2" json:"value"`
+	Mode       string    `gorm:"not null;size:20;default:include" json:"mode"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (VoucherTarget) TableName() string {
+	return "voucher_targets"
+}
+
+func (t *VoucherTarget) IsExclude() bool {
+	return t.Mode == TargetModeExclude
+}
+
+// Matches reports whether the cart line is the SKU or category this target names.
+func (t *VoucherTarget) Matches(line CartLine) bool {
+	switch t.TargetType {
+	case TargetTypeSKU:
+		return strings.EqualFold(t.Value, line.SKU)
+	case TargetTypeCategory:
+		return strings.EqualFold(t.Value, line.Category)
+	}
+	return false
+}
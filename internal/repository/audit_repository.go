@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository only appends and reads; audit entries are never changed.
+type AuditRepository interface {
+	Create(entries []models.AuditLog) error
+	FindAll(query dto.AuditLogListQuery) ([]models.AuditLog, int64, error)
+	WithTx(tx *gorm.DB) AuditRepository
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(entries []models.AuditLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(&entries, 1000).Error
+}
+
+func (r *auditRepository) FindAll(query dto.AuditLogListQuery) ([]models.AuditLog, int64, error) {
+	var entries []models.AuditLog
+	var total int64
+
+	db := r.db.Model(&models.AuditLog{})
+
+	if query.VoucherID > 0 {
+		db = db.Where("voucher_id = ?", query.VoucherID)
+	}
+
+	if query.Code != "" {
+		db = db.Where("voucher_code = ?", query.Code)
+	}
+
+	if query.Actor != "" {
+		db = db.Where("actor = ?", query.Actor)
+	}
+
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+
+	if query.RequestID != "" {
+		db = db.Where("request_id = ?", query.RequestID)
+	}
+
+	if query.Field != "" {
+		// Changes is a JSON object keyed by field name
+		db = db.Where("jsonb_exists(changes::jsonb, ?)", query.Field)
+	}
+
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+	offset := (page - 1) * pageSize
+
+	err := db.Order("created_at desc, id desc").Offset(offset).Limit(pageSize).Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+func (r *auditRepository) WithTx(tx *gorm.DB) AuditRepository {
+	return &auditRepository{db: tx}
+}
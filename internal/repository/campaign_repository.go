@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CampaignRepository interface {
+	Create(campaign *models.Campaign) error
+	FindByID(id uint) (*models.Campaign, error)
+	FindAll(query dto.CampaignListQuery) ([]models.Campaign, int64, error)
+	Update(campaign *models.Campaign) error
+	Delete(id uint) error
+	CountVouchers(id uint) (int64, error)
+	LockVouchers(id uint) error
+	SetVoucherStatus(id uint, from, to string) ([]models.Voucher, error)
+	DetachVouchers(id uint) ([]models.Voucher, error)
+	FindByIDForUpdate(id uint) (*models.Campaign, error)
+	UpdateBudgetUsage(campaign *models.Campaign) error
+	WithTx(tx *gorm.DB) CampaignRepository
+	Transaction(fn func(tx *gorm.DB) error) error
+}
+
+type campaignRepository struct {
+	db *gorm.DB
+}
+
+func NewCampaignRepository(db *gorm.DB) CampaignRepository {
+	return &campaignRepository{db: db}
+}
+
+func (r *campaignRepository) Create(campaign *models.Campaign) error {
+	return r.db.Create(campaign).Error
+}
+
+func (r *campaignRepository) FindByID(id uint) (*models.Campaign, error) {
+	var campaign models.Campaign
+	err := r.db.First(&campaign, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *campaignRepository) FindAll(query dto.CampaignListQuery) ([]models.Campaign, int64, error) {
+	var campaigns []models.Campaign
+	var total int64
+
+	db := r.db.Model(&models.Campaign{})
+
+	if query.Search != "" {
+		searchPattern := "%" + strings.ToLower(query.Search) + "%"
+		db = db.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", searchPattern, searchPattern)
+	}
+
+	if query.Owner != "" {
+		db = db.Where("owner = ?", query.Owner)
+	}
+
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+	offset := (page - 1) * pageSize
+
+	err := db.Order("created_at desc, id desc").Offset(offset).Limit(pageSize).Find(&campaigns).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return campaigns, total, nil
+}
+
+func (r *campaignRepository) Update(campaign *models.Campaign) error {
+	return r.db.Save(campaign).Error
+}
+
+func (r *campaignRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Campaign{}, id).Error
+}
+
+func (r *campaignRepository) CountVouchers(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Voucher{}).Where("campaign_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// LockVouchers holds row locks on the campaign's vouchers until the
+// surrounding transaction ends. The rows are locked in ID order, and before
+// the campaign itself, the order redemptions lock a voucher and its campaign.
+func (r *campaignRepository) LockVouchers(id uint) error {
+	var ids []uint
+	return r.db.Model(&models.Voucher{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("campaign_id = ?", id).
+		Order("id").
+		Pluck("id", &ids).Error
+}
+
+// SetVoucherStatus moves the campaign's vouchers in status from to status to
+// and returns the vouchers that changed, as they are after the update.
+// Vouchers it pauses are marked as paused by the campaign, and only those are
+// resumed again, so vouchers an operator paused stay paused.
+func (r *campaignRepository) SetVoucherStatus(id uint, from, to string) ([]models.Voucher, error) {
+	var vouchers []models.Voucher
+	pausing := to == models.VoucherStatusPaused
+
+	db := r.db.Model(&vouchers).
+		Clauses(clause.Returning{}).
+		Where("campaign_id = ? AND status = ?", id, from)
+	if !pausing {
+		db = db.Where("paused_by_campaign = ?", true)
+	}
+	err := db.Updates(map[string]interface{}{
+		"status":             to,
+		"paused_by_campaign": pausing,
+		"version":            nextVersion,
+	}).Error
+	return vouchers, err
+}
+
+// DetachVouchers removes the campaign from its vouchers, which then no longer
+// count against any budget, and returns the detached vouchers. Vouchers the
+// campaign paused stay paused, now as if an operator had paused them.
+func (r *campaignRepository) DetachVouchers(id uint) ([]models.Voucher, error) {
+	var vouchers []models.Voucher
+	err := r.db.Model(&vouchers).
+		Clauses(clause.Returning{}).
+		Where("campaign_id = ?", id).
+		Updates(map[string]interface{}{"campaign_id": nil, "paused_by_campaign": false, "version": nextVersion}).Error
+	return vouchers, err
+}
+
+// FindByIDForUpdate loads a campaign and holds a row lock on it until the
+// surrounding transaction ends, so concurrent redemptions cannot overspend the budget.
+func (r *campaignRepository) FindByIDForUpdate(id uint) (*models.Campaign, error) {
+	var campaign models.Campaign
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&campaign, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *campaignRepository) UpdateBudgetUsage(campaign *models.Campaign) error {
+	return r.db.Model(campaign).UpdateColumns(map[string]interface{}{
+		"budget_used":     campaign.BudgetUsed,
+		"budget_reserved": campaign.BudgetReserved,
+	}).Error
+}
+
+func (r *campaignRepository) WithTx(tx *gorm.DB) CampaignRepository {
+	return &campaignRepository{db: tx}
+}
+
+func (r *campaignRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
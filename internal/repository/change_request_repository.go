@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChangeRequestRepository interface {
+	Create(change *models.VoucherChangeRequest) error
+	FindByID(id uint) (*models.VoucherChangeRequest, error)
+	FindAll(query dto.ChangeRequestListQuery) ([]models.VoucherChangeRequest, int64, error)
+	FindByIDForUpdate(id uint) (*models.VoucherChangeRequest, error)
+	Update(change *models.VoucherChangeRequest) error
+	WithTx(tx *gorm.DB) ChangeRequestRepository
+	Transaction(fn func(tx *gorm.DB) error) error
+}
+
+type changeRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewChangeRequestRepository(db *gorm.DB) ChangeRequestRepository {
+	return &changeRequestRepository{db: db}
+}
+
+func (r *changeRequestRepository) Create(change *models.VoucherChangeRequest) error {
+	return r.db.Create(change).Error
+}
+
+func (r *changeRequestRepository) FindByID(id uint) (*models.VoucherChangeRequest, error) {
+	var change models.VoucherChangeRequest
+	err := r.db.First(&change, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *changeRequestRepository) FindAll(query dto.ChangeRequestListQuery) ([]models.VoucherChangeRequest, int64, error) {
+	var changes []models.VoucherChangeRequest
+	var total int64
+
+	db := r.db.Model(&models.VoucherChangeRequest{})
+
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+
+	if query.VoucherID > 0 {
+		db = db.Where("voucher_id = ?", query.VoucherID)
+	}
+
+	if query.RequestedBy != "" {
+		db = db.Where("requested_by = ?", query.RequestedBy)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+	offset := (page - 1) * pageSize
+
+	err := db.Order("created_at desc, id desc").Offset(offset).Limit(pageSize).Find(&changes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return changes, total, nil
+}
+
+// FindByIDForUpdate loads a change request and holds a row lock on it, so two
+// reviewers cannot approve or reject it at the same time.
+func (r *changeRequestRepository) FindByIDForUpdate(id uint) (*models.VoucherChangeRequest, error) {
+	var change models.VoucherChangeRequest
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&change, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *changeRequestRepository) Update(change *models.VoucherChangeRequest) error {
+	return r.db.Save(change).Error
+}
+
+func (r *changeRequestRepository) WithTx(tx *gorm.DB) ChangeRequestRepository {
+	return &changeRequestRepository{db: tx}
+}
+
+func (r *changeRequestRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
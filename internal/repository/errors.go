@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrStaleVersion means a versioned write found the row at another version
+// than the one it was read at, because another write got there first.
+var ErrStaleVersion = errors.New("row has been changed by another write")
+
+// pgUniqueViolation is the Postgres SQLSTATE for a unique constraint violation.
+const pgUniqueViolation = "23505"
+
+// VoucherCodeIndex is the partial unique index that keeps the codes of live
+// vouchers unique. Soft-deleted vouchers are outside it, so their codes can
+// be used again.
+const VoucherCodeIndex = "idx_vouchers_code_live"
+
+// IsDuplicateKeyError reports whether err comes from a unique constraint.
+func IsDuplicateKeyError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// IsCodeConflictError reports whether err comes from another live voucher
+// already holding the code.
+func IsCodeConflictError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == VoucherCodeIndex
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	CreateIfAbsent(record *models.IdempotencyKey) (bool, error)
+	FindByKey(scope, key string) (*models.IdempotencyKey, error)
+	Update(record *models.IdempotencyKey) error
+	Delete(id uint) error
+	DeleteExpired(now time.Time, limit int) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// CreateIfAbsent inserts the record unless the scope/key pair is already taken.
+// It reports whether this call claimed the key.
+func (r *idempotencyRepository) CreateIfAbsent(record *models.IdempotencyKey) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *idempotencyRepository) FindByKey(scope, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	err := r.db.Where("scope = ? AND key = ?", scope, key).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Update(record *models.IdempotencyKey) error {
+	return r.db.Save(record).Error
+}
+
+func (r *idempotencyRepository) Delete(id uint) error {
+	return r.db.Delete(&models.IdempotencyKey{}, id).Error
+}
+
+// DeleteExpired removes up to limit keys that expired before now, oldest
+// first, and returns how many it removed.
+func (r *idempotencyRepository) DeleteExpired(now time.Time, limit int) (int64, error) {
+	expired := r.db.Model(&models.IdempotencyKey{}).
+		Select("id").
+		Where("expires_at < ?", now).
+		Order("expires_at asc").
+		Limit(limit)
+	result := r.db.Where("id IN (?)", expired).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RedemptionRepository interface {
+	Create(redemption *models.Redemption) error
+	FindByIDForUpdate(id uint) (*models.Redemption, error)
+	FindByVoucherID(voucherID uint, query dto.RedemptionListQuery) ([]models.Redemption, int64, error)
+	CountByCustomer(voucherID uint, customerRef string) (int64, error)
+	FindExpiredReservationIDs(now time.Time, limit int) ([]uint, error)
+	Update(redemption *models.Redemption) error
+	WithTx(tx *gorm.DB) RedemptionRepository
+}
+
+type redemptionRepository struct {
+	db *gorm.DB
+}
+
+func NewRedemptionRepository(db *gorm.DB) RedemptionRepository {
+	return &redemptionRepository{db: db}
+}
+
+func (r *redemptionRepository) Create(redemption *models.Redemption) error {
+	return r.db.Create(redemption).Error
+}
+
+func (r *redemptionRepository) FindByIDForUpdate(id uint) (*models.Redemption, error) {
+	var redemption models.Redemption
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&redemption, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &redemption, nil
+}
+
+func (r *redemptionRepository) FindByVoucherID(voucherID uint, query dto.RedemptionListQuery) ([]models.Redemption, int64, error) {
+	var redemptions []models.Redemption
+	var total int64
+
+	db := r.db.Model(&models.Redemption{}).Where("voucher_id = ?", voucherID)
+
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+	offset := (page - 1) * pageSize
+
+	err := db.Order("created_at desc, id desc").Offset(offset).Limit(pageSize).Find(&redemptions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return redemptions, total, nil
+}
+
+// CountByCustomer counts the redemptions and open reservations of a voucher by one customer.
+func (r *redemptionRepository) CountByCustomer(voucherID uint, customerRef string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Redemption{}).
+		Where("voucher_id = ? AND customer_ref = ?", voucherID, customerRef).
+		Where("status IN ?", []string{models.RedemptionStatusRedeemed, models.RedemptionStatusReserved}).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *redemptionRepository) FindExpiredReservationIDs(now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Redemption{}).
+		Where("status = ? AND expires_at <= ?", models.RedemptionStatusReserved, now).
+		Order("expires_at asc").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *redemptionRepository) Update(redemption *models.Redemption) error {
+	return r.db.Save(redemption).Error
+}
+
+func (r *redemptionRepository) WithTx(tx *gorm.DB) RedemptionRepository {
+	return &redemptionRepository{db: tx}
+}
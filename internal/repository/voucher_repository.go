@@ -3,21 +3,47 @@ package repository
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/rifqi142/indico-be/internal/dto"
 	"github.com/rifqi142/indico-be/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type VoucherRepository interface {
 	Create(voucher *models.Voucher) error
 	FindByID(id uint) (*models.Voucher, error)
 	FindByCode(code string) (*models.Voucher, error)
-	FindAll(query dto.VoucherListQuery) ([]models.Voucher, int64, error)
+	FindAll(query dto.VoucherListQuery, after *VoucherKey, offset, limit int) ([]models.Voucher, error)
+	Count(filter dto.VoucherFilter) (int64, error)
+	FindIDs(filter dto.VoucherFilter, limit int) ([]uint, error)
 	Update(voucher *models.Voucher) error
+	ReplaceTargets(voucherID uint, targets []models.VoucherTarget) error
+	ReplaceSchedules(voucherID uint, schedules []models.VoucherSchedule) error
 	Delete(id uint) error
-	BulkCreate(vouchers []models.Voucher) (int, []string)
-	ExportAll() ([]models.Voucher, error)
+	FindTrashed(query dto.TrashListQuery) ([]models.Voucher, int64, error)
+	FindTrashedIDs(deletedBefore time.Time, limit int) ([]uint, error)
+	FindTrashedByIDForUpdate(id uint) (*models.Voucher, error)
+	Restore(voucher *models.Voucher) error
+	Purge(id uint) error
+	CreateInBatches(vouchers []models.Voucher, batchSize int) error
+	FindExistingCodes(codes []string) ([]string, error)
+	Export(filter dto.VoucherFilter) ([]models.Voucher, error)
+	FindByCodeForUpdate(code string) (*models.Voucher, error)
+	FindByIDForUpdate(id uint) (*models.Voucher, error)
+	UpdateStatus(voucher *models.Voucher) error
+	UpdateUsage(voucher *models.Voucher) error
+	WithTx(tx *gorm.DB) VoucherRepository
+	Unscoped() VoucherRepository
+	Transaction(fn func(tx *gorm.DB) error) error
+}
+
+// VoucherKey is a voucher's place in a sorted list: its value in the sort
+// column and its ID, which breaks ties between equal values.
+type VoucherKey struct {
+	Value interface{}
+	ID    uint
 }
 
 type voucherRepository struct {
@@ -34,7 +60,7 @@ func (r *voucherRepository) Create(voucher *models.Voucher) error {
 
 func (r *voucherRepository) FindByID(id uint) (*models.Voucher, error) {
 	var voucher models.Voucher
-	err := r.db.First(&voucher, id).Error
+	err := r.db.Preload("Targets").Preload("Schedules").Preload("Campaign").First(&voucher, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -43,45 +69,297 @@ func (r *voucherRepository) FindByID(id uint) (*models.Voucher, error) {
 
 func (r *voucherRepository) FindByCode(code string) (*models.Voucher, error) {
 	var voucher models.Voucher
-	err := r.db.Where("code = ?", code).First(&voucher).Error
+	err := r.db.Preload("Targets").Preload("Schedules").Preload("Campaign").Where("code = ?", code).First(&voucher).Error
 	if err != nil {
 		return nil, err
 	}
 	return &voucher, nil
 }
 
-func (r *voucherRepository) FindAll(query dto.VoucherListQuery) ([]models.Voucher, int64, error) {
+// FindAll returns up to limit vouchers matching the query's filters in its
+// sort order, with the ID breaking ties so the order is stable. Given a key,
+// the list starts right after that voucher (keyset pagination), which stays
+// fast deep into the table and does not shift when rows are inserted;
+// otherwise it skips offset vouchers.
+func (r *voucherRepository) FindAll(query dto.VoucherListQuery, after *VoucherKey, offset, limit int) ([]models.Voucher, error) {
 	var vouchers []models.Voucher
+
+	db := r.filter(query.VoucherFilter)
+
+	sortBy, sortOrder := query.Sort()
+	if after != nil {
+		comparison := ">"
+		if sortOrder == "desc" {
+			comparison = "<"
+		}
+		if sortBy == "id" {
+			db = db.Where(fmt.Sprintf("id %s ?", comparison), after.ID)
+		} else {
+			db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortBy, comparison), after.Value, after.ID)
+		}
+	} else {
+		db = db.Offset(offset)
+	}
+
+	if sortBy != "id" {
+		db = db.Order(fmt.Sprintf("%s %s", sortBy, sortOrder))
+	}
+	db = db.Order("id " + sortOrder)
+
+	err := db.Limit(limit).Preload("Targets").Preload("Schedules").Preload("Campaign").Find(&vouchers).Error
+	return vouchers, err
+}
+
+// Count returns how many vouchers match the filter.
+func (r *voucherRepository) Count(filter dto.VoucherFilter) (int64, error) {
 	var total int64
+	err := r.filter(filter).Count(&total).Error
+	return total, err
+}
+
+// FindIDs returns the IDs of up to limit vouchers matching the filter, in ID
+// order.
+func (r *voucherRepository) FindIDs(filter dto.VoucherFilter, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.filter(filter).Order("id asc").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// searchCondition matches a search term against the code, name and
+// description. Codes are stored normalized, so the code is matched against the
+// normalized term, and "welcome-2025" finds WELCOME2025 as FindByCode does.
+func searchCondition(db *gorm.DB, term string) *gorm.DB {
+	searchPattern := "%" + strings.ToLower(term) + "%"
+	condition := db.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", searchPattern, searchPattern)
+	if code := models.NormalizeVoucherCode(term); code != "" {
+		condition = condition.Or("code LIKE ?", "%"+code+"%")
+	}
+	return condition
+}
 
+// filter applies the conditions of a voucher filter.
+func (r *voucherRepository) filter(filter dto.VoucherFilter) *gorm.DB {
 	db := r.db.Model(&models.Voucher{})
 
-	// Apply filter conditions
-	if query.Search != "" {
-		searchPattern := "%" + strings.ToLower(query.Search) + "%"
-		db = db.Where(
-			"LOWER(code) LIKE ? OR LOWER(name) LIKE ? OR LOWER(description) LIKE ?",
-			searchPattern, searchPattern, searchPattern,
-		)
+	if filter.Search != "" {
+		db = db.Where(searchCondition(r.db, filter.Search))
 	}
 
-	if query.IsActive != nil {
-		db = db.Where("is_active = ?", *query.IsActive)
+	if codes := filter.CodeList(); len(codes) > 0 {
+		db = db.Where("vouchers.code IN ?", codes)
 	}
 
-	if err := db.Count(&total).Error; err != nil {
-		return nil, 0, err
+	// Lifecycle filters use the derived state, so an expired voucher is not "active"
+	now := time.Now()
+	if filter.IsActive != nil {
+		condition, args := stateCondition(models.StateActive, now)
+		if *filter.IsActive {
+			db = db.Where(condition, args...)
+		} else {
+			db = db.Not(condition, args...)
+		}
+	}
+
+	if filter.Status != "" {
+		condition, args := stateCondition(models.VoucherState(filter.Status), now)
+		db = db.Where(condition, args...)
+	}
+
+	if filter.ComputedStatus != "" {
+		condition, args := computedStatusCondition(filter.ComputedStatus, now)
+		db = db.Where(condition, args...)
 	}
 
-	sortBy := "created_at"
-	sortOrder := "asc"
-	if query.SortBy != "" {
-		sortBy = query.SortBy
+	if filter.DiscountType != "" {
+		db = db.Where("vouchers.discount_type = ?", filter.DiscountType)
+	}
+	if filter.DiscountMin != nil {
+		db = db.Where("vouchers.discount >= ?", int64(*filter.DiscountMin))
+	}
+	if filter.DiscountMax != nil {
+		db = db.Where("vouchers.discount <= ?", int64(*filter.DiscountMax))
+	}
+
+	// Usage counts open holds as well, the same as the exhausted state
+	if filter.UsageMin != nil {
+		db = db.Where("vouchers.used_count + vouchers.reserved_count >= CAST(? AS NUMERIC) * vouchers.max_usage", *filter.UsageMin)
+	}
+	if filter.UsageMax != nil {
+		db = db.Where("vouchers.used_count + vouchers.reserved_count <= CAST(? AS NUMERIC) * vouchers.max_usage", *filter.UsageMax)
+	}
+
+	db = timeRange(db, "vouchers.valid_from", filter.ValidFromAfter, filter.ValidFromBefore)
+	db = timeRange(db, "vouchers.valid_until", filter.ValidUntilAfter, filter.ValidUntilBefore)
+	db = timeRange(db, "vouchers.created_at", filter.CreatedAfter, filter.CreatedBefore)
+
+	if filter.CampaignID != 0 {
+		db = db.Where("campaign_id = ?", filter.CampaignID)
+	}
+
+	if filter.SKU != "" {
+		db = db.Where("EXISTS (?)", r.targetSubQuery(models.TargetTypeSKU, filter.SKU, filter.TargetMode))
+	}
+
+	if filter.Category != "" {
+		db = db.Where("EXISTS (?)", r.targetSubQuery(models.TargetTypeCategory, filter.Category, filter.TargetMode))
+	}
+
+	return db
+}
+
+// timeRange keeps rows with column at or after after and before before,
+// skipping a bound that is not set.
+func timeRange(db *gorm.DB, column string, after, before time.Time) *gorm.DB {
+	if !after.IsZero() {
+		db = db.Where(column+" >= ?", after)
+	}
+	if !before.IsZero() {
+		db = db.Where(column+" < ?", before)
+	}
+	return db
+}
+
+// stateCondition is the SQL form of Voucher.State for one state at the given
+// time, so list filters agree with the state reported for each voucher.
+func stateCondition(state models.VoucherState, now time.Time) (string, []interface{}) {
+	const started = "vouchers.status = ? AND vouchers.valid_until > ? AND vouchers.valid_from < ?"
+
+	switch state {
+	case models.StateDraft:
+		return "vouchers.status = ?", []interface{}{models.VoucherStatusDraft}
+	case models.StateArchived:
+		return "vouchers.status = ?", []interface{}{models.VoucherStatusArchived}
+	case models.StateExpired:
+		return "vouchers.status IN ? AND vouchers.valid_until <= ?",
+			[]interface{}{[]string{models.VoucherStatusActive, models.VoucherStatusPaused}, now}
+	case models.StatePaused:
+		return "vouchers.status = ? AND vouchers.valid_until > ?", []interface{}{models.VoucherStatusPaused, now}
+	case models.StateScheduled:
+		return "vouchers.status = ? AND vouchers.valid_until > ? AND vouchers.valid_from >= ?",
+			[]interface{}{models.VoucherStatusActive, now, now}
+	case models.StateExhausted:
+		return started + " AND vouchers.used_count + vouchers.reserved_count >= vouchers.max_usage",
+			[]interface{}{models.VoucherStatusActive, now, now}
+	case models.StateActive:
+		return started + " AND vouchers.used_count + vouchers.reserved_count < vouchers.max_usage",
+			[]interface{}{models.VoucherStatusActive, now, now}
+	}
+	return "1 = 0", nil
+}
+
+// computedStatusCondition is the SQL form of a computed status at the given
+// time. Unlike stateCondition it ignores the lifecycle status, so a paused
+// voucher inside its validity period is still running.
+func computedStatusCondition(status string, now time.Time) (string, []interface{}) {
+	const started = "vouchers.valid_from < ? AND vouchers.valid_until > ?"
+	const usedUp = "vouchers.used_count + vouchers.reserved_count >= vouchers.max_usage"
+
+	switch status {
+	case dto.ComputedStatusUpcoming:
+		return "vouchers.valid_from >= ?", []interface{}{now}
+	case dto.ComputedStatusExpired:
+		return "vouchers.valid_until <= ?", []interface{}{now}
+	case dto.ComputedStatusExhausted:
+		return started + " AND " + usedUp, []interface{}{now, now}
+	case dto.ComputedStatusRunning:
+		return started + " AND NOT (" + usedUp + ")", []interface{}{now, now}
+	}
+	return "1 = 0", nil
+}
+
+// targetSubQuery selects the targets of the outer voucher that name the given SKU or category.
+func (r *voucherRepository) targetSubQuery(targetType, value, mode string) *gorm.DB {
+	sub := r.db.Model(&models.VoucherTarget{}).
+		Select("1").
+		Where("voucher_targets.voucher_id = vouchers.id").
+		Where("voucher_targets.target_type = ? AND LOWER(voucher_targets.value) = ?", targetType, strings.ToLower(value))
+	if mode != "" {
+		sub = sub.Where("voucher_targets.mode = ?", mode)
+	}
+	return sub
+}
+
+// nextVersion is the update expression that moves a voucher to its next
+// version. Every write to a voucher row goes through it, so the version, and
+// with it the voucher's ETag, changes whenever the stored voucher does.
+var nextVersion = gorm.Expr("version + 1")
+
+// Update writes the voucher only if it is still at the version it was read
+// at, and returns ErrStaleVersion otherwise. On success the voucher holds its
+// new version.
+func (r *voucherRepository) Update(voucher *models.Voucher) error {
+	version := voucher.Version
+	voucher.Version++
+	result := r.db.Model(voucher).
+		Omit(clause.Associations).
+		Select("*").
+		Where("version = ?", version).
+		Updates(voucher)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrStaleVersion
+	}
+	if result.Error != nil {
+		voucher.Version = version
+	}
+	return result.Error
+}
+
+// ReplaceTargets swaps the voucher's targets for the given list in one transaction.
+func (r *voucherRepository) ReplaceTargets(voucherID uint, targets []models.VoucherTarget) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("voucher_id = ?", voucherID).Delete(&models.VoucherTarget{}).Error; err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			return nil
+		}
+		for i := range targets {
+			targets[i].ID = 0
+			targets[i].VoucherID = voucherID
+		}
+		return tx.Create(&targets).Error
+	})
+}
+
+// ReplaceSchedules swaps the voucher's recurring windows for the given list in one transaction.
+func (r *voucherRepository) ReplaceSchedules(voucherID uint, schedules []models.VoucherSchedule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("voucher_id = ?", voucherID).Delete(&models.VoucherSchedule{}).Error; err != nil {
+			return err
+		}
+		if len(schedules) == 0 {
+			return nil
+		}
+		for i := range schedules {
+			schedules[i].ID = 0
+			schedules[i].VoucherID = voucherID
+		}
+		return tx.Create(&schedules).Error
+	})
+}
+
+func (r *voucherRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Voucher{}, id).Error
+}
+
+// trashed scopes a query to soft-deleted vouchers only.
+func (r *voucherRepository) trashed() *gorm.DB {
+	return r.db.Unscoped().Model(&models.Voucher{}).Where("deleted_at IS NOT NULL")
+}
+
+func (r *voucherRepository) FindTrashed(query dto.TrashListQuery) ([]models.Voucher, int64, error) {
+	var vouchers []models.Voucher
+	var total int64
+
+	db := r.trashed()
+
+	if query.Search != "" {
+		db = db.Where(searchCondition(r.db, query.Search))
 	}
-	if query.SortOrder != "" {
-		sortOrder = query.SortOrder
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
-	db = db.Order(fmt.Sprintf("%s %s", sortBy, sortOrder))
 
 	page := 1
 	pageSize := 10
@@ -92,40 +370,182 @@ func (r *voucherRepository) FindAll(query dto.VoucherListQuery) ([]models.Vouche
 		pageSize = query.PageSize
 	}
 	offset := (page - 1) * pageSize
-	db = db.Offset(offset).Limit(pageSize)
 
-	if err := db.Find(&vouchers).Error; err != nil {
+	err := db.Preload("Targets").Preload("Schedules").
+		Order("deleted_at desc, id desc").
+		Offset(offset).Limit(pageSize).
+		Find(&vouchers).Error
+	if err != nil {
 		return nil, 0, err
 	}
 
 	return vouchers, total, nil
 }
 
-func (r *voucherRepository) Update(voucher *models.Voucher) error {
-	return r.db.Save(voucher).Error
+// FindTrashedIDs lists vouchers soft-deleted before the given time, oldest
+// first, for the retention purge.
+func (r *voucherRepository) FindTrashedIDs(deletedBefore time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.trashed().
+		Where("deleted_at < ?", deletedBefore).
+		Order("deleted_at asc").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
 }
 
-func (r *voucherRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Voucher{}, id).Error
+// FindTrashedByIDForUpdate loads a soft-deleted voucher under a row lock, so
+// it cannot be restored and purged at the same time.
+func (r *voucherRepository) FindTrashedByIDForUpdate(id uint) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := r.trashed().Clauses(clause.Locking{Strength: "UPDATE"}).First(&voucher, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Loaded separately so the lock only covers the voucher row
+	if err := r.db.Where("voucher_id = ?", voucher.ID).Find(&voucher.Targets).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("voucher_id = ?", voucher.ID).Find(&voucher.Schedules).Error; err != nil {
+		return nil, err
+	}
+	return &voucher, nil
 }
 
-func (r *voucherRepository) BulkCreate(vouchers []models.Voucher) (int, []string) {
-	successCount := 0
-	var errors []string
+// Restore brings a soft-deleted voucher back, under its current code and
+// campaign, which the caller may have changed.
+func (r *voucherRepository) Restore(voucher *models.Voucher) error {
+	err := r.db.Unscoped().Model(voucher).Updates(map[string]interface{}{
+		"deleted_at":  nil,
+		"code":        voucher.Code,
+		"campaign_id": voucher.CampaignID,
+		"version":     nextVersion,
+	}).Error
+	if err != nil {
+		return err
+	}
+	voucher.Version++
+	return nil
+}
 
-	for i, voucher := range vouchers {
-		if err := r.db.Create(&voucher).Error; err != nil {
-			errors = append(errors, fmt.Sprintf("Row %d: %s", i+1, err.Error()))
-		} else {
-			successCount++
-		}
+// Purge permanently deletes a voucher with its targets and schedules. Its
+// redemptions and audit entries are kept.
+func (r *voucherRepository) Purge(id uint) error {
+	if err := r.db.Where("voucher_id = ?", id).Delete(&models.VoucherTarget{}).Error; err != nil {
+		return err
 	}
+	if err := r.db.Where("voucher_id = ?", id).Delete(&models.VoucherSchedule{}).Error; err != nil {
+		return err
+	}
+	return r.db.Unscoped().Delete(&models.Voucher{}, id).Error
+}
 
-	return successCount, errors
+// CreateInBatches inserts the vouchers, with their targets and schedules, using
+// multi-row inserts. Either every voucher is created or none is.
+func (r *voucherRepository) CreateInBatches(vouchers []models.Voucher, batchSize int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&vouchers, batchSize).Error
+	})
 }
 
-func (r *voucherRepository) ExportAll() ([]models.Voucher, error) {
+// FindExistingCodes returns which of the codes live vouchers already use.
+func (r *voucherRepository) FindExistingCodes(codes []string) ([]string, error) {
+	const chunkSize = 5000
+
+	var existing []string
+	for start := 0; start < len(codes); start += chunkSize {
+		end := min(start+chunkSize, len(codes))
+
+		var found []string
+		err := r.db.Model(&models.Voucher{}).
+			Where("code IN ?", codes[start:end]).
+			Pluck("code", &found).Error
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, found...)
+	}
+	return existing, nil
+}
+
+func (r *voucherRepository) Export(filter dto.VoucherFilter) ([]models.Voucher, error) {
 	var vouchers []models.Voucher
-	err := r.db.Order("created_at desc").Find(&vouchers).Error
+	err := r.filter(filter).Preload("Targets").Preload("Schedules").Preload("Campaign").Order("created_at desc").Find(&vouchers).Error
 	return vouchers, err
 }
+
+// FindByCodeForUpdate loads a voucher and holds a row lock on it until the
+// surrounding transaction ends, so concurrent redemptions are serialized.
+func (r *voucherRepository) FindByCodeForUpdate(code string) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		First(&voucher).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Loaded separately so the lock only covers the voucher row
+	if err := r.db.Where("voucher_id = ?", voucher.ID).Find(&voucher.Targets).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("voucher_id = ?", voucher.ID).Find(&voucher.Schedules).Error; err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *voucherRepository) FindByIDForUpdate(id uint) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&voucher, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+// UpdateUsage expects the voucher to be locked, so it moves it to the next
+// version without checking the one it was read at. UpdateStatus does the same.
+func (r *voucherRepository) UpdateUsage(voucher *models.Voucher) error {
+	err := r.db.Model(voucher).UpdateColumns(map[string]interface{}{
+		"used_count":     voucher.UsedCount,
+		"reserved_count": voucher.ReservedCount,
+		"version":        nextVersion,
+	}).Error
+	if err != nil {
+		return err
+	}
+	voucher.Version++
+	return nil
+}
+
+// UpdateStatus also clears PausedByCampaign: once an operator changes the
+// status directly, resuming the campaign no longer resumes the voucher.
+func (r *voucherRepository) UpdateStatus(voucher *models.Voucher) error {
+	err := r.db.Model(voucher).Updates(map[string]interface{}{
+		"status":             voucher.Status,
+		"paused_by_campaign": false,
+		"version":            nextVersion,
+	}).Error
+	if err != nil {
+		return err
+	}
+	voucher.PausedByCampaign = false
+	voucher.Version++
+	return nil
+}
+
+func (r *voucherRepository) WithTx(tx *gorm.DB) VoucherRepository {
+	return &voucherRepository{db: tx}
+}
+
+// Unscoped returns a repository that also finds and writes soft-deleted
+// vouchers, for paths that must settle usage on a voucher in the trash.
+func (r *voucherRepository) Unscoped() VoucherRepository {
+	return &voucherRepository{db: r.db.Unscoped()}
+}
+
+func (r *voucherRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
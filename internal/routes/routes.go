@@ -4,15 +4,23 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rifqi142/indico-be/internal/controllers"
 	"github.com/rifqi142/indico-be/internal/middleware"
+	"github.com/rifqi142/indico-be/internal/services"
 )
 
 func SetupRoutes(
 	router *gin.Engine,
 	authController *controllers.AuthController,
 	voucherController *controllers.VoucherController,
+	redemptionController *controllers.RedemptionController,
+	campaignController *controllers.CampaignController,
+	changeRequestController *controllers.ChangeRequestController,
+	auditController *controllers.AuditController,
+	trashController *controllers.TrashController,
+	idempotencyService services.IdempotencyService,
 	jwtSecret string,
 ) {
 	router.Use(middleware.CORSMiddleware())
+	router.Use(middleware.RequestIDMiddleware())
 
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -25,18 +33,63 @@ func SetupRoutes(
 
 	api := router.Group("/")
 	api.Use(middleware.AuthMiddleware(jwtSecret))
+	idempotent := middleware.IdempotencyMiddleware(idempotencyService)
 	{
 		vouchers := api.Group("/vouchers")
 		{
 			vouchers.GET("", voucherController.GetAllVouchers)
 			vouchers.GET("/get-by-id/:id", voucherController.GetVoucherByID)
-			vouchers.POST("", voucherController.CreateVoucher)
+			vouchers.POST("", idempotent, voucherController.CreateVoucher)
 			vouchers.PUT("/:id", voucherController.UpdateVoucher)
+			vouchers.PATCH("/:id", voucherController.PatchVoucher)
 			vouchers.DELETE("/:id", voucherController.DeleteVoucher)
+			vouchers.POST("/bulk", idempotent, voucherController.BulkUpdateVouchers)
+			vouchers.POST("/:id/generate-codes", idempotent, voucherController.GenerateCodes)
+			vouchers.POST("/:id/publish", voucherController.PublishVoucher)
+			vouchers.POST("/:id/pause", voucherController.PauseVoucher)
+			vouchers.POST("/:id/resume", voucherController.ResumeVoucher)
+			vouchers.POST("/:id/archive", voucherController.ArchiveVoucher)
+
+			// Trash operations
+			vouchers.GET("/trash", trashController.GetTrash)
+			vouchers.POST("/trash/:id/restore", idempotent, trashController.RestoreVoucher)
+			vouchers.DELETE("/trash/:id", trashController.PurgeVoucher)
+
+			// Redemption operations
+			vouchers.POST("/validate", redemptionController.ValidateVoucher)
+			vouchers.POST("/combine", redemptionController.CombineVouchers)
+			vouchers.POST("/redeem", idempotent, redemptionController.RedeemVoucher)
+			vouchers.POST("/reserve", idempotent, redemptionController.ReserveVoucher)
+			vouchers.POST("/redemptions/:id/confirm", idempotent, redemptionController.ConfirmReservation)
+			vouchers.POST("/redemptions/:id/release", idempotent, redemptionController.ReleaseReservation)
+			vouchers.GET("/:id/redemptions", redemptionController.GetRedemptionsByVoucher)
+			vouchers.GET("/:id/history", auditController.GetVoucherHistory)
+			vouchers.POST("/redemptions/:id/reverse", idempotent, redemptionController.ReverseRedemption)
 			
 			// CSV operations
 			vouchers.POST("/upload-csv", voucherController.UploadCSV)
 			vouchers.GET("/export", voucherController.ExportCSV)
 		}
+
+		campaigns := api.Group("/campaigns")
+		{
+			campaigns.GET("", campaignController.GetAllCampaigns)
+			campaigns.GET("/:id", campaignController.GetCampaignByID)
+			campaigns.POST("", idempotent, campaignController.CreateCampaign)
+			campaigns.PUT("/:id", campaignController.UpdateCampaign)
+			campaigns.DELETE("/:id", campaignController.DeleteCampaign)
+			campaigns.POST("/:id/activate", campaignController.ActivateCampaign)
+			campaigns.POST("/:id/pause", campaignController.PauseCampaign)
+		}
+
+		api.GET("/audit-logs", auditController.GetAuditLogs)
+
+		changeRequests := api.Group("/change-requests")
+		{
+			changeRequests.GET("", changeRequestController.GetAllChangeRequests)
+			changeRequests.GET("/:id", changeRequestController.GetChangeRequestByID)
+			changeRequests.POST("/:id/approve", idempotent, changeRequestController.ApproveChangeRequest)
+			changeRequests.POST("/:id/reject", idempotent, changeRequestController.RejectChangeRequest)
+		}
 	}
 }
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/repository"
+)
+
+// Actor is who made a change and the request it came from, as recorded in
+// the audit trail.
+type Actor struct {
+	Username  string
+	RequestID string
+}
+
+// SystemActor makes the changes of background jobs, such as expiring holds.
+var SystemActor = Actor{Username: "system"}
+
+type AuditService interface {
+	GetVoucherHistory(voucherID uint, query dto.AuditLogListQuery) (*dto.AuditLogListResponse, error)
+	GetAuditLogs(query dto.AuditLogListQuery) (*dto.AuditLogListResponse, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// GetVoucherHistory lists the voucher's audit entries, newest first. It also
+// works for deleted vouchers, whose history is kept.
+func (s *auditService) GetVoucherHistory(voucherID uint, query dto.AuditLogListQuery) (*dto.AuditLogListResponse, error) {
+	query.VoucherID = voucherID
+	return s.GetAuditLogs(query)
+}
+
+func (s *auditService) GetAuditLogs(query dto.AuditLogListQuery) (*dto.AuditLogListResponse, error) {
+	if query.Code != "" {
+		query.Code = models.NormalizeVoucherCode(query.Code)
+	}
+
+	entries, total, err := s.repo.FindAll(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+
+	entryResponses := make([]dto.AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		entryResponses[i] = *toAuditLogResponse(&entry)
+	}
+
+	return &dto.AuditLogListResponse{
+		Data: entryResponses,
+		Pagination: dto.PaginationMeta{
+			CurrentPage: page,
+			PageSize:    pageSize,
+			TotalPages:  totalPages,
+			TotalItems:  total,
+		},
+	}, nil
+}
+
+type auditChange struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+type auditTarget struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Mode  string `json:"mode"`
+}
+
+type auditSchedule struct {
+	Days      string `json:"days"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// newAuditEntry records a voucher going from before to after. Before is nil
+// for a create and after is nil for a delete.
+func newAuditEntry(actor Actor, action string, before, after *models.Voucher) models.AuditLog {
+	entry := models.AuditLog{
+		Action:    action,
+		Actor:     actor.Username,
+		RequestID: actor.RequestID,
+		Changes:   voucherDiff(before, after),
+	}
+
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	if subject != nil {
+		entry.VoucherID = subject.ID
+		entry.VoucherCode = subject.Code
+	}
+	return entry
+}
+
+// newRedemptionAuditEntry records what a redemption did to its voucher. The
+// voucher may have been deleted since, in which case nothing changed on it.
+func newRedemptionAuditEntry(actor Actor, action string, redemption *models.Redemption, before, after *models.Voucher) models.AuditLog {
+	entry := newAuditEntry(actor, action, before, after)
+	entry.VoucherID = redemption.VoucherID
+	entry.VoucherCode = redemption.VoucherCode
+	entry.RedemptionID = &redemption.ID
+	return entry
+}
+
+// voucherDiff lists the fields that differ between the two vouchers as a JSON
+// object of field name to {"from", "to"}, with null for a missing side.
+func voucherDiff(before, after *models.Voucher) string {
+	from := voucherSnapshot(before)
+	to := voucherSnapshot(after)
+
+	changes := make(map[string]auditChange)
+	for field, value := range to {
+		if !bytes.Equal(from[field], value) {
+			changes[field] = auditChange{From: jsonOrNull(from[field]), To: value}
+		}
+	}
+	for field, value := range from {
+		if _, ok := to[field]; !ok {
+			changes[field] = auditChange{From: value, To: jsonOrNull(nil)}
+		}
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// voucherSnapshot flattens the voucher's own fields to JSON values by field
+// name. IDs, timestamps and the version are left out, times are compared in UTC and
+// targets and schedules only by their content.
+func voucherSnapshot(voucher *models.Voucher) map[string]json.RawMessage {
+	if voucher == nil {
+		return nil
+	}
+
+	copied := *voucher
+	copied.ValidFrom = copied.ValidFrom.UTC()
+	copied.ValidUntil = copied.ValidUntil.UTC()
+	copied.Targets = nil
+	copied.Schedules = nil
+	copied.Campaign = nil
+
+	data, err := json.Marshal(copied)
+	if err != nil {
+		return nil
+	}
+	var snapshot map[string]json.RawMessage
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	for _, field := range []string{"id", "version", "created_at", "updated_at", "deleted_at", "targets", "schedules", "campaign"} {
+		delete(snapshot, field)
+	}
+
+	targets := make([]auditTarget, len(voucher.Targets))
+	for i, target := range voucher.Targets {
+		targets[i] = auditTarget{Type: target.TargetType, Value: target.Value, Mode: target.Mode}
+	}
+	schedules := make([]auditSchedule, len(voucher.Schedules))
+	for i, schedule := range voucher.Schedules {
+		schedules[i] = auditSchedule{Days: schedule.Days, StartTime: schedule.StartTime, EndTime: schedule.EndTime}
+	}
+	snapshot["targets"], _ = json.Marshal(targets)
+	snapshot["schedules"], _ = json.Marshal(schedules)
+
+	return snapshot
+}
+
+func jsonOrNull(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return value
+}
+
+func toAuditLogResponse(entry *models.AuditLog) *dto.AuditLogResponse {
+	return &dto.AuditLogResponse{
+		ID:              entry.ID,
+		VoucherID:       entry.VoucherID,
+		VoucherCode:     entry.VoucherCode,
+		RedemptionID:    entry.RedemptionID,
+		ChangeRequestID: entry.ChangeRequestID,
+		Action:          entry.Action,
+		Actor:           entry.Actor,
+		RequestID:       entry.RequestID,
+		Changes:         json.RawMessage(entry.Changes),
+		CreatedAt:       entry.CreatedAt,
+	}
+}
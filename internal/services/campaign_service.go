@@ -0,0 +1,318 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/repository"
+	"github.com/rifqi142/indico-be/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCampaignNotFound      = errors.New("campaign not found")
+	ErrInvalidCampaignDates  = errors.New("campaign ends_at must be after starts_at")
+	ErrBudgetBelowUsage      = errors.New("campaign budget cannot be lower than the discount already used or reserved")
+	ErrCampaignCurrency      = errors.New("voucher currency must match the campaign currency")
+	ErrCampaignStatusUnknown = errors.New("unknown campaign status")
+)
+
+type CampaignService interface {
+	CreateCampaign(req dto.CreateCampaignRequest) (*dto.CampaignResponse, error)
+	GetCampaignByID(id uint) (*dto.CampaignResponse, error)
+	GetAllCampaigns(query dto.CampaignListQuery) (*dto.CampaignListResponse, error)
+	UpdateCampaign(id uint, req dto.UpdateCampaignRequest) (*dto.CampaignResponse, error)
+	DeleteCampaign(id uint, actor Actor) error
+	SetCampaignStatus(id uint, status string, actor Actor) (*dto.CampaignStatusResponse, error)
+}
+
+type campaignService struct {
+	repo      repository.CampaignRepository
+	auditRepo repository.AuditRepository
+}
+
+func NewCampaignService(repo repository.CampaignRepository, auditRepo repository.AuditRepository) CampaignService {
+	return &campaignService{repo: repo, auditRepo: auditRepo}
+}
+
+func (s *campaignService) CreateCampaign(req dto.CreateCampaignRequest) (*dto.CampaignResponse, error) {
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	if !models.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("unsupported currency %q", currency)
+	}
+
+	campaign := &models.Campaign{
+		Name:        req.Name,
+		Description: req.Description,
+		Owner:       strings.TrimSpace(req.Owner),
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		Currency:    currency,
+		Budget:      req.Budget,
+		Status:      models.CampaignStatusActive,
+	}
+
+	if err := s.repo.Create(campaign); err != nil {
+		return nil, err
+	}
+
+	return toCampaignResponse(campaign, 0), nil
+}
+
+func (s *campaignService) GetCampaignByID(id uint) (*dto.CampaignResponse, error) {
+	campaign, err := s.findCampaign(s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountVouchers(campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCampaignResponse(campaign, count), nil
+}
+
+func (s *campaignService) GetAllCampaigns(query dto.CampaignListQuery) (*dto.CampaignListResponse, error) {
+	campaigns, total, err := s.repo.FindAll(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+
+	campaignResponses := make([]dto.CampaignResponse, len(campaigns))
+	for i, campaign := range campaigns {
+		count, err := s.repo.CountVouchers(campaign.ID)
+		if err != nil {
+			return nil, err
+		}
+		campaignResponses[i] = *toCampaignResponse(&campaign, count)
+	}
+
+	return &dto.CampaignListResponse{
+		Data: campaignResponses,
+		Pagination: dto.PaginationMeta{
+			CurrentPage: page,
+			PageSize:    pageSize,
+			TotalPages:  totalPages,
+			TotalItems:  total,
+		},
+	}, nil
+}
+
+// UpdateCampaign locks the campaign so the new budget is checked against
+// usage that concurrent redemptions cannot change underneath it.
+func (s *campaignService) UpdateCampaign(id uint, req dto.UpdateCampaignRequest) (*dto.CampaignResponse, error) {
+	var campaign *models.Campaign
+	var count int64
+
+	err := s.repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+
+		locked, err := txRepo.FindByIDForUpdate(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCampaignNotFound
+			}
+			return err
+		}
+
+		if req.Name != "" {
+			locked.Name = req.Name
+		}
+		if req.Description != nil {
+			locked.Description = *req.Description
+		}
+		if req.Owner != "" {
+			locked.Owner = strings.TrimSpace(req.Owner)
+		}
+		if !req.StartsAt.IsZero() {
+			locked.StartsAt = req.StartsAt
+		}
+		if !req.EndsAt.IsZero() {
+			locked.EndsAt = req.EndsAt
+		}
+		if req.Budget != nil {
+			locked.Budget = *req.Budget
+		}
+
+		if !locked.EndsAt.After(locked.StartsAt) {
+			return ErrInvalidCampaignDates
+		}
+		if locked.HasBudget() && locked.RemainingBudget() < 0 {
+			return ErrBudgetBelowUsage
+		}
+
+		if err := txRepo.Update(locked); err != nil {
+			return err
+		}
+
+		count, err = txRepo.CountVouchers(locked.ID)
+		if err != nil {
+			return err
+		}
+
+		campaign = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toCampaignResponse(campaign, count), nil
+}
+
+// DeleteCampaign soft deletes the campaign and detaches its vouchers, which
+// keep working without a budget.
+func (s *campaignService) DeleteCampaign(id uint, actor Actor) error {
+	if _, err := s.findCampaign(s.repo, id); err != nil {
+		return err
+	}
+
+	return s.repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+		if err := txRepo.LockVouchers(id); err != nil {
+			return err
+		}
+		detached, err := txRepo.DetachVouchers(id)
+		if err != nil {
+			return err
+		}
+		if err := txRepo.Delete(id); err != nil {
+			return err
+		}
+
+		entries := make([]models.AuditLog, len(detached))
+		for i := range detached {
+			before := detached[i]
+			before.CampaignID = &id
+			entries[i] = newAuditEntry(actor, models.AuditActionUpdate, &before, &detached[i])
+		}
+		return s.auditRepo.WithTx(tx).Create(entries)
+	})
+}
+
+// SetCampaignStatus activates or pauses the campaign and resumes or pauses
+// its vouchers to match, in one transaction. Activating resumes only the
+// vouchers the campaign paused; draft, archived and individually paused
+// vouchers are left alone. Each voucher changed is audited as a resume or pause.
+func (s *campaignService) SetCampaignStatus(id uint, status string, actor Actor) (*dto.CampaignStatusResponse, error) {
+	if status != models.CampaignStatusActive && status != models.CampaignStatusPaused {
+		return nil, ErrCampaignStatusUnknown
+	}
+
+	var campaign *models.Campaign
+	var affected, count int64
+
+	err := s.repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+
+		// The vouchers are locked before the campaign, the order redemptions
+		// take them in, so a redemption running alongside cannot deadlock
+		if err := txRepo.LockVouchers(id); err != nil {
+			return err
+		}
+		locked, err := txRepo.FindByIDForUpdate(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCampaignNotFound
+			}
+			return err
+		}
+
+		locked.Status = status
+		if err := txRepo.Update(locked); err != nil {
+			return err
+		}
+
+		from, to := models.VoucherStatusPaused, models.VoucherStatusActive
+		action := models.TransitionResume
+		if status == models.CampaignStatusPaused {
+			from, to = to, from
+			action = models.TransitionPause
+		}
+		changed, err := txRepo.SetVoucherStatus(locked.ID, from, to)
+		if err != nil {
+			return err
+		}
+
+		entries := make([]models.AuditLog, len(changed))
+		for i := range changed {
+			before := changed[i]
+			before.Status = from
+			entries[i] = newAuditEntry(actor, string(action), &before, &changed[i])
+		}
+		if err := s.auditRepo.WithTx(tx).Create(entries); err != nil {
+			return err
+		}
+		affected = int64(len(changed))
+
+		count, err = txRepo.CountVouchers(locked.ID)
+		if err != nil {
+			return err
+		}
+
+		campaign = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.CampaignStatusResponse{
+		Campaign:         *toCampaignResponse(campaign, count),
+		VouchersAffected: affected,
+	}, nil
+}
+
+func (s *campaignService) findCampaign(repo repository.CampaignRepository, id uint) (*models.Campaign, error) {
+	campaign, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, err
+	}
+	return campaign, nil
+}
+
+func toCampaignResponse(campaign *models.Campaign, voucherCount int64) *dto.CampaignResponse {
+	response := &dto.CampaignResponse{
+		ID:             campaign.ID,
+		Name:           campaign.Name,
+		Description:    campaign.Description,
+		Owner:          campaign.Owner,
+		StartsAt:       utils.NewReadableTime(campaign.StartsAt),
+		EndsAt:         utils.NewReadableTime(campaign.EndsAt),
+		Currency:       campaign.Currency,
+		Budget:         campaign.Budget,
+		BudgetUsed:     campaign.BudgetUsed,
+		BudgetReserved: campaign.BudgetReserved,
+		Status:         campaign.Status,
+		VoucherCount:   voucherCount,
+		CreatedAt:      utils.NewReadableTime(campaign.CreatedAt),
+		UpdatedAt:      utils.NewReadableTime(campaign.UpdatedAt),
+	}
+	if campaign.HasBudget() {
+		remaining := campaign.RemainingBudget()
+		response.RemainingBudget = &remaining
+	}
+	return response
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/repository"
+	"gorm.io/gorm"
+)
+
+// idempotencyPurgeBatchSize bounds each delete of expired keys, so a large
+// backlog is removed in short statements.
+const idempotencyPurgeBatchSize = 1000
+
+var (
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key was already used with a different request payload")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+type IdempotencyService interface {
+	Begin(scope, key, requestHash string) (*models.IdempotencyKey, error)
+	Complete(record *models.IdempotencyKey, statusCode int, headers map[string]string, body []byte) error
+	Release(record *models.IdempotencyKey) error
+	PurgeExpired() (int64, error)
+}
+
+type idempotencyService struct {
+	repo repository.IdempotencyRepository
+	ttl  time.Duration
+}
+
+func NewIdempotencyService(repo repository.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	return &idempotencyService{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+// Begin claims the key for a new request. When the key was already used with
+// the same payload, the stored record is returned and IsCompleted reports true
+// so the caller can replay the original response.
+func (s *idempotencyService) Begin(scope, key, requestHash string) (*models.IdempotencyKey, error) {
+	record := &models.IdempotencyKey{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	}
+
+	// A second attempt is needed when an expired record had to be removed first
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := s.repo.CreateIfAbsent(record)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return record, nil
+		}
+
+		existing, err := s.repo.FindByKey(scope, key)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		if existing.IsExpired() {
+			if err := s.repo.Delete(existing.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, ErrIdempotencyKeyMismatch
+		}
+		if !existing.IsCompleted() {
+			return nil, ErrIdempotencyKeyInProgress
+		}
+
+		return existing, nil
+	}
+
+	return nil, ErrIdempotencyKeyInProgress
+}
+
+// Complete stores the response of the request that claimed the key, with the
+// headers to send again on replays.
+func (s *idempotencyService) Complete(record *models.IdempotencyKey, statusCode int, headers map[string]string, body []byte) error {
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record.StatusCode = statusCode
+	record.ResponseHeaders = string(encoded)
+	record.ResponseBody = body
+	record.CompletedAt = &now
+	return s.repo.Update(record)
+}
+
+// Release drops a claimed key so the client can retry after a server error
+// or a panic.
+func (s *idempotencyService) Release(record *models.IdempotencyKey) error {
+	return s.repo.Delete(record.ID)
+}
+
+// PurgeExpired deletes every expired key in batches and returns how many
+// were deleted. Begin already ignores expired keys, this only frees the space.
+func (s *idempotencyService) PurgeExpired() (int64, error) {
+	now := time.Now()
+	var purged int64
+	for {
+		deleted, err := s.repo.DeleteExpired(now, idempotencyPurgeBatchSize)
+		purged += deleted
+		if err != nil || deleted < idempotencyPurgeBatchSize {
+			return purged, err
+		}
+	}
+}
@@ -0,0 +1,766 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/repository"
+	"github.com/rifqi142/indico-be/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrVoucherNotRedeemable      = errors.New("voucher is not valid or has reached its usage limit")
+	ErrRedemptionNotFound        = errors.New("redemption not found")
+	ErrRedemptionAlreadyReversed = errors.New("redemption has already been reversed")
+	ErrRedemptionNotRedeemed     = errors.New("only redeemed redemptions can be reversed")
+	ErrRedemptionNotReserved     = errors.New("redemption is not an open reservation")
+	ErrReservationExpired        = errors.New("reservation has expired")
+	ErrCustomerRefRequired       = errors.New("customer_ref must not be blank")
+)
+
+// reservationSweepBatchSize bounds how many expired holds one sweep releases.
+const reservationSweepBatchSize = 100
+
+// VoucherUnavailableError carries the reason a voucher cannot be redeemed.
+type VoucherUnavailableError struct {
+	Reason models.ValidationReason
+}
+
+func (e *VoucherUnavailableError) Error() string {
+	return e.Reason.Message()
+}
+
+func (e *VoucherUnavailableError) Unwrap() error {
+	return ErrVoucherNotRedeemable
+}
+
+type RedemptionService interface {
+	ValidateVoucher(req dto.ValidateVoucherRequest) (*dto.ValidateVoucherResponse, error)
+	CombineVouchers(req dto.CombineVouchersRequest) (*dto.CombineVouchersResponse, error)
+	RedeemVoucher(req dto.RedeemVoucherRequest, actor Actor) (*dto.RedeemVoucherResponse, error)
+	ReserveVoucher(req dto.RedeemVoucherRequest, actor Actor) (*dto.ReserveVoucherResponse, error)
+	ConfirmReservation(id uint, actor Actor) (*dto.RedemptionResponse, error)
+	ReleaseReservation(id uint, actor Actor) (*dto.RedemptionResponse, error)
+	ReleaseExpiredReservations() (int, error)
+	GetRedemptionsByVoucher(voucherID uint, query dto.RedemptionListQuery) (*dto.RedemptionListResponse, error)
+	ReverseRedemption(id uint, req dto.ReverseRedemptionRequest, actor Actor) (*dto.RedemptionResponse, error)
+}
+
+type redemptionService struct {
+	voucherRepo    repository.VoucherRepository
+	redemptionRepo repository.RedemptionRepository
+	campaignRepo   repository.CampaignRepository
+	auditRepo      repository.AuditRepository
+	reservationTTL time.Duration
+}
+
+func NewRedemptionService(voucherRepo repository.VoucherRepository, redemptionRepo repository.RedemptionRepository, campaignRepo repository.CampaignRepository, auditRepo repository.AuditRepository, reservationTTL time.Duration) RedemptionService {
+	return &redemptionService{
+		voucherRepo:    voucherRepo,
+		redemptionRepo: redemptionRepo,
+		campaignRepo:   campaignRepo,
+		auditRepo:      auditRepo,
+		reservationTTL: reservationTTL,
+	}
+}
+
+// ValidateVoucher quotes the discount for a cart without consuming the voucher.
+func (s *redemptionService) ValidateVoucher(req dto.ValidateVoucherRequest) (*dto.ValidateVoucherResponse, error) {
+	code := models.NormalizeVoucherCode(req.Code)
+	cart := newCart(req.CartAmount, req.Currency, req.ItemCount, req.Items)
+	response := &dto.ValidateVoucherResponse{
+		Code:        code,
+		Currency:    cart.Currency,
+		CartAmount:  cart.Amount,
+		FinalAmount: cart.Amount,
+	}
+
+	voucher, err := s.voucherRepo.FindByCode(code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Reason = string(models.ReasonNotFound)
+			response.Message = models.ReasonNotFound.Message()
+			return response, nil
+		}
+		return nil, err
+	}
+
+	response.Name = voucher.Name
+	reason, err := s.checkVoucher(voucher, cart, req.CustomerRef)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		response.Reason = string(reason)
+		response.Message = reason.Message()
+		return response, nil
+	}
+
+	response.Valid = true
+	response.EligibleAmount = voucher.ApplicableAmount(cart)
+	response.DiscountAmount = voucher.DiscountForCart(cart)
+	response.FinalAmount = cart.Amount - response.DiscountAmount
+	return response, nil
+}
+
+// CombineVouchers picks the allowed combination of several codes for one
+// cart: either the single best exclusive voucher, or stackable vouchers with
+// at most one per exclusivity group, whichever gives the larger discount.
+// Every discount is computed on the cart lines its voucher applies to and
+// the total never exceeds the cart amount. Nothing is consumed.
+func (s *redemptionService) CombineVouchers(req dto.CombineVouchersRequest) (*dto.CombineVouchersResponse, error) {
+	cart := newCart(req.CartAmount, req.Currency, req.ItemCount, req.Items)
+	response := &dto.CombineVouchersResponse{
+		Currency:    cart.Currency,
+		CartAmount:  cart.Amount,
+		Applied:     []dto.AppliedVoucher{},
+		Rejected:    []dto.RejectedVoucher{},
+		FinalAmount: cart.Amount,
+	}
+
+	reject := func(code string, reason models.ValidationReason) {
+		response.Rejected = append(response.Rejected, dto.RejectedVoucher{
+			Code:    code,
+			Reason:  string(reason),
+			Message: reason.Message(),
+		})
+	}
+
+	var bestExclusive *models.Voucher
+	var exclusives []*models.Voucher
+	var candidates []*models.Voucher
+	groupWinners := make(map[string]*models.Voucher)
+	seen := make(map[string]bool)
+
+	for _, rawCode := range req.Codes {
+		code := models.NormalizeVoucherCode(rawCode)
+		if seen[code] {
+			reject(code, models.ReasonDuplicate)
+			continue
+		}
+		seen[code] = true
+
+		voucher, err := s.voucherRepo.FindByCode(code)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				reject(code, models.ReasonNotFound)
+				continue
+			}
+			return nil, err
+		}
+
+		reason, err := s.checkVoucher(voucher, cart, req.CustomerRef)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			reject(voucher.Code, reason)
+			continue
+		}
+
+		discount := voucher.DiscountForCart(cart)
+		if !voucher.IsStackable() {
+			exclusives = append(exclusives, voucher)
+			if bestExclusive == nil || discount > bestExclusive.DiscountForCart(cart) {
+				bestExclusive = voucher
+			}
+			continue
+		}
+
+		candidates = append(candidates, voucher)
+		if group := voucher.ExclusivityGroup; group != "" {
+			winner, ok := groupWinners[group]
+			if !ok || discount > winner.DiscountForCart(cart) {
+				groupWinners[group] = voucher
+			}
+		}
+	}
+
+	// Keep one stackable voucher per exclusivity group, in request order
+	var stackables []*models.Voucher
+	var stackedDiscount models.Money
+	for _, voucher := range candidates {
+		if group := voucher.ExclusivityGroup; group != "" && groupWinners[group] != voucher {
+			reject(voucher.Code, models.ReasonGroupConflict)
+			continue
+		}
+		stackables = append(stackables, voucher)
+		stackedDiscount += voucher.DiscountForCart(cart)
+	}
+
+	// Use the exclusive voucher only when it beats the stacked vouchers
+	applied := stackables
+	var notCombined []*models.Voucher
+	if bestExclusive != nil && bestExclusive.DiscountForCart(cart) > stackedDiscount {
+		applied = []*models.Voucher{bestExclusive}
+		notCombined = stackables
+	}
+	for _, voucher := range exclusives {
+		if len(applied) != 1 || applied[0] != voucher {
+			notCombined = append(notCombined, voucher)
+		}
+	}
+	for _, voucher := range notCombined {
+		reject(voucher.Code, models.ReasonNotCombinable)
+	}
+
+	for _, voucher := range applied {
+		discount := voucher.DiscountForCart(cart)
+		if remaining := cart.Amount - response.TotalDiscount; discount > remaining {
+			discount = remaining
+		}
+		response.Applied = append(response.Applied, dto.AppliedVoucher{
+			Code:           voucher.Code,
+			Name:           voucher.Name,
+			DiscountAmount: discount,
+		})
+		response.TotalDiscount += discount
+	}
+	response.FinalAmount = cart.Amount - response.TotalDiscount
+
+	return response, nil
+}
+
+// checkVoucher runs every non-consuming rule against a cart. The per-customer
+// limit is only checked when the customer is known.
+func (s *redemptionService) checkVoucher(voucher *models.Voucher, cart models.Cart, customerRef string) (models.ValidationReason, error) {
+	if reason := voucher.CheckOrder(time.Now(), cart); reason != "" {
+		return reason, nil
+	}
+	if voucher.Campaign != nil && !voucher.Campaign.CanCover(voucher.DiscountForCart(cart)) {
+		return models.ReasonCampaignBudget, nil
+	}
+
+	customerRef = strings.TrimSpace(customerRef)
+	if customerRef == "" || voucher.MaxUsagePerCustomer == 0 {
+		return "", nil
+	}
+
+	used, err := s.redemptionRepo.CountByCustomer(voucher.ID, customerRef)
+	if err != nil {
+		return "", err
+	}
+	return voucher.CheckCustomerUsage(used), nil
+}
+
+func (s *redemptionService) RedeemVoucher(req dto.RedeemVoucherRequest, actor Actor) (*dto.RedeemVoucherResponse, error) {
+	redemption, voucher, err := s.claimVoucher(req, false, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RedeemVoucherResponse{
+		Redemption:     *toRedemptionResponse(redemption),
+		Voucher:        *toVoucherResponse(voucher),
+		RemainingUsage: voucher.RemainingUsage(),
+	}, nil
+}
+
+// ReserveVoucher places a hold on one use of the voucher. The hold counts
+// against MaxUsage until it is confirmed, released or expires.
+func (s *redemptionService) ReserveVoucher(req dto.RedeemVoucherRequest, actor Actor) (*dto.ReserveVoucherResponse, error) {
+	reservation, voucher, err := s.claimVoucher(req, true, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReserveVoucherResponse{
+		Reservation:    *toRedemptionResponse(reservation),
+		Voucher:        *toVoucherResponse(voucher),
+		RemainingUsage: voucher.RemainingUsage(),
+	}, nil
+}
+
+// claimVoucher checks every redemption rule and records either a confirmed
+// redemption or a reservation in a single transaction.
+func (s *redemptionService) claimVoucher(req dto.RedeemVoucherRequest, reserve bool, actor Actor) (*models.Redemption, *models.Voucher, error) {
+	// Blank refs would all share one per-customer count
+	if strings.TrimSpace(req.CustomerRef) == "" {
+		return nil, nil, ErrCustomerRefRequired
+	}
+
+	var voucher *models.Voucher
+	var redemption *models.Redemption
+
+	err := s.voucherRepo.Transaction(func(tx *gorm.DB) error {
+		voucherRepo := s.voucherRepo.WithTx(tx)
+		redemptionRepo := s.redemptionRepo.WithTx(tx)
+		campaignRepo := s.campaignRepo.WithTx(tx)
+
+		// Lock the row so concurrent redemptions cannot exceed MaxUsage or the
+		// per-customer limit, since every redemption of the voucher waits here
+		locked, err := voucherRepo.FindByCodeForUpdate(models.NormalizeVoucherCode(req.Code))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVoucherNotFound
+			}
+			return err
+		}
+
+		// The campaign is locked after the voucher. Paths that lock several of
+		// a campaign's vouchers take them in ID order, also before the campaign
+		if locked.CampaignID != nil {
+			locked.Campaign, err = campaignRepo.FindByIDForUpdate(*locked.CampaignID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		now := time.Now()
+		cart := newCart(req.OrderAmount, req.Currency, req.ItemCount, req.Items)
+		if reason := locked.CheckOrder(now, cart); reason != "" {
+			return &VoucherUnavailableError{Reason: reason}
+		}
+
+		discount := locked.DiscountForCart(cart)
+		campaign := locked.Campaign
+		if campaign != nil && !campaign.CanCover(discount) {
+			return &VoucherUnavailableError{Reason: models.ReasonCampaignBudget}
+		}
+
+		customerRef := strings.TrimSpace(req.CustomerRef)
+		if locked.MaxUsagePerCustomer > 0 {
+			used, err := redemptionRepo.CountByCustomer(locked.ID, customerRef)
+			if err != nil {
+				return err
+			}
+			if reason := locked.CheckCustomerUsage(used); reason != "" {
+				return &VoucherUnavailableError{Reason: reason}
+			}
+		}
+
+		redemption = &models.Redemption{
+			VoucherID:      locked.ID,
+			VoucherCode:    locked.Code,
+			CustomerRef:    customerRef,
+			OrderRef:       strings.TrimSpace(req.OrderRef),
+			OrderAmount:    cart.Amount,
+			DiscountAmount: discount,
+			Currency:       locked.Currency,
+		}
+		if campaign != nil {
+			redemption.CampaignID = &campaign.ID
+		}
+
+		before := *locked
+		action := models.AuditActionRedeem
+		if reserve {
+			action = models.AuditActionReserve
+			expiresAt := now.Add(s.reservationTTL)
+			locked.Reserve()
+			if campaign != nil {
+				campaign.Hold(discount)
+			}
+			redemption.Status = models.RedemptionStatusReserved
+			redemption.ReservedAt = &now
+			redemption.ExpiresAt = &expiresAt
+		} else {
+			locked.IncrementUsage()
+			if campaign != nil {
+				campaign.Spend(discount)
+			}
+			redemption.Status = models.RedemptionStatusRedeemed
+			redemption.RedeemedAt = &now
+		}
+
+		if err := voucherRepo.UpdateUsage(locked); err != nil {
+			return err
+		}
+		if campaign != nil {
+			if err := campaignRepo.UpdateBudgetUsage(campaign); err != nil {
+				return err
+			}
+		}
+		if err := redemptionRepo.Create(redemption); err != nil {
+			return err
+		}
+		if err := s.audit(tx, newRedemptionAuditEntry(actor, action, redemption, &before, locked)); err != nil {
+			return err
+		}
+
+		voucher = locked
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return redemption, voucher, nil
+}
+
+// ConfirmReservation turns an open hold into a redemption. A hold that has
+// already run out is released instead and ErrReservationExpired is returned.
+func (s *redemptionService) ConfirmReservation(id uint, actor Actor) (*dto.RedemptionResponse, error) {
+	var redemption *models.Redemption
+	expired := false
+
+	err := s.voucherRepo.Transaction(func(tx *gorm.DB) error {
+		// Unscoped so a hold on a voucher in the trash is still settled
+		voucherRepo := s.voucherRepo.WithTx(tx).Unscoped()
+		redemptionRepo := s.redemptionRepo.WithTx(tx)
+
+		locked, err := s.lockReservation(redemptionRepo, id)
+		if err != nil {
+			return err
+		}
+
+		if locked.IsReservationExpired(time.Now()) {
+			expired = true
+			return s.releaseHold(tx, locked, models.RedemptionStatusExpired, actor)
+		}
+
+		voucher, err := voucherRepo.FindByIDForUpdate(locked.VoucherID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		var before models.Voucher
+		if voucher != nil {
+			before = *voucher
+			voucher.ConfirmReservation()
+			if err := voucherRepo.UpdateUsage(voucher); err != nil {
+				return err
+			}
+		}
+		if err := s.updateCampaignBudget(tx, locked, (*models.Campaign).ConfirmHold); err != nil {
+			return err
+		}
+
+		locked.Confirm()
+		if err := redemptionRepo.Update(locked); err != nil {
+			return err
+		}
+		if err := s.audit(tx, newRedemptionAuditEntry(actor, models.AuditActionConfirm, locked, voucherOrNil(&before, voucher), voucher)); err != nil {
+			return err
+		}
+
+		redemption = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrReservationExpired
+	}
+
+	return toRedemptionResponse(redemption), nil
+}
+
+func (s *redemptionService) ReleaseReservation(id uint, actor Actor) (*dto.RedemptionResponse, error) {
+	var redemption *models.Redemption
+
+	err := s.voucherRepo.Transaction(func(tx *gorm.DB) error {
+		locked, err := s.lockReservation(s.redemptionRepo.WithTx(tx), id)
+		if err != nil {
+			return err
+		}
+
+		status := models.RedemptionStatusReleased
+		if locked.IsReservationExpired(time.Now()) {
+			status = models.RedemptionStatusExpired
+		}
+
+		if err := s.releaseHold(tx, locked, status, actor); err != nil {
+			return err
+		}
+
+		redemption = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toRedemptionResponse(redemption), nil
+}
+
+// ReleaseExpiredReservations gives the usage of expired holds back to their
+// vouchers and reports how many holds were released.
+func (s *redemptionService) ReleaseExpiredReservations() (int, error) {
+	ids, err := s.redemptionRepo.FindExpiredReservationIDs(time.Now(), reservationSweepBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, id := range ids {
+		err := s.voucherRepo.Transaction(func(tx *gorm.DB) error {
+			locked, err := s.redemptionRepo.WithTx(tx).FindByIDForUpdate(id)
+			if err != nil {
+				return err
+			}
+
+			// Confirmed or released since it was listed
+			if !locked.IsReservationExpired(time.Now()) {
+				return nil
+			}
+
+			if err := s.releaseHold(tx, locked, models.RedemptionStatusExpired, SystemActor); err != nil {
+				return err
+			}
+
+			released++
+			return nil
+		})
+		if err != nil {
+			return released, err
+		}
+	}
+
+	return released, nil
+}
+
+func (s *redemptionService) lockReservation(redemptionRepo repository.RedemptionRepository, id uint) (*models.Redemption, error) {
+	locked, err := redemptionRepo.FindByIDForUpdate(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRedemptionNotFound
+		}
+		return nil, err
+	}
+
+	if !locked.IsReserved() {
+		return nil, ErrRedemptionNotReserved
+	}
+	return locked, nil
+}
+
+// releaseHold ends a locked reservation and frees its slot on the voucher,
+// also when the voucher is in the trash, so it is restored without the hold.
+// A purged voucher has nothing to restore. The audit action is release or
+// expire, following the status.
+func (s *redemptionService) releaseHold(tx *gorm.DB, reservation *models.Redemption, status string, actor Actor) error {
+	voucherRepo := s.voucherRepo.WithTx(tx).Unscoped()
+
+	voucher, err := voucherRepo.FindByIDForUpdate(reservation.VoucherID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	var before models.Voucher
+	if voucher != nil {
+		before = *voucher
+		voucher.ReleaseReservation()
+		if err := voucherRepo.UpdateUsage(voucher); err != nil {
+			return err
+		}
+	}
+	if err := s.updateCampaignBudget(tx, reservation, (*models.Campaign).ReleaseHold); err != nil {
+		return err
+	}
+
+	reservation.Release(status)
+	if err := s.redemptionRepo.WithTx(tx).Update(reservation); err != nil {
+		return err
+	}
+
+	action := models.AuditActionRelease
+	if status == models.RedemptionStatusExpired {
+		action = models.AuditActionExpire
+	}
+	return s.audit(tx, newRedemptionAuditEntry(actor, action, reservation, voucherOrNil(&before, voucher), voucher))
+}
+
+func (s *redemptionService) audit(tx *gorm.DB, entry models.AuditLog) error {
+	return s.auditRepo.WithTx(tx).Create([]models.AuditLog{entry})
+}
+
+// voucherOrNil returns before when the voucher was found and nil otherwise,
+// so a missing voucher is audited without a diff.
+func voucherOrNil(before, voucher *models.Voucher) *models.Voucher {
+	if voucher == nil {
+		return nil
+	}
+	return before
+}
+
+// updateCampaignBudget locks the campaign the redemption was charged to and
+// applies one of the Campaign budget methods with the redemption discount.
+// Redemptions outside a campaign, or of a deleted one, have nothing to update.
+func (s *redemptionService) updateCampaignBudget(tx *gorm.DB, redemption *models.Redemption, apply func(*models.Campaign, models.Money)) error {
+	if redemption.CampaignID == nil {
+		return nil
+	}
+
+	campaignRepo := s.campaignRepo.WithTx(tx)
+	campaign, err := campaignRepo.FindByIDForUpdate(*redemption.CampaignID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	apply(campaign, redemption.DiscountAmount)
+	return campaignRepo.UpdateBudgetUsage(campaign)
+}
+
+func (s *redemptionService) GetRedemptionsByVoucher(voucherID uint, query dto.RedemptionListQuery) (*dto.RedemptionListResponse, error) {
+	if _, err := s.voucherRepo.FindByID(voucherID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVoucherNotFound
+		}
+		return nil, err
+	}
+
+	redemptions, total, err := s.redemptionRepo.FindByVoucherID(voucherID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+
+	redemptionResponses := make([]dto.RedemptionResponse, len(redemptions))
+	for i, redemption := range redemptions {
+		redemptionResponses[i] = *toRedemptionResponse(&redemption)
+	}
+
+	return &dto.RedemptionListResponse{
+		Data: redemptionResponses,
+		Pagination: dto.PaginationMeta{
+			CurrentPage: page,
+			PageSize:    pageSize,
+			TotalPages:  totalPages,
+			TotalItems:  total,
+		},
+	}, nil
+}
+
+func (s *redemptionService) ReverseRedemption(id uint, req dto.ReverseRedemptionRequest, actor Actor) (*dto.RedemptionResponse, error) {
+	var redemption *models.Redemption
+
+	err := s.voucherRepo.Transaction(func(tx *gorm.DB) error {
+		voucherRepo := s.voucherRepo.WithTx(tx).Unscoped()
+		redemptionRepo := s.redemptionRepo.WithTx(tx)
+
+		locked, err := redemptionRepo.FindByIDForUpdate(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRedemptionNotFound
+			}
+			return err
+		}
+
+		if locked.IsReversed() {
+			return ErrRedemptionAlreadyReversed
+		}
+		if !locked.IsRedeemed() {
+			return ErrRedemptionNotRedeemed
+		}
+
+		// Give the usage back, also to a voucher in the trash; a purged voucher has nothing to restore
+		voucher, err := voucherRepo.FindByIDForUpdate(locked.VoucherID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		var before models.Voucher
+		if voucher != nil {
+			before = *voucher
+			voucher.DecrementUsage()
+			if err := voucherRepo.UpdateUsage(voucher); err != nil {
+				return err
+			}
+		}
+		if err := s.updateCampaignBudget(tx, locked, (*models.Campaign).Refund); err != nil {
+			return err
+		}
+
+		locked.Reverse(strings.TrimSpace(req.Reason))
+		if err := redemptionRepo.Update(locked); err != nil {
+			return err
+		}
+		if err := s.audit(tx, newRedemptionAuditEntry(actor, models.AuditActionReverse, locked, voucherOrNil(&before, voucher), voucher)); err != nil {
+			return err
+		}
+
+		redemption = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toRedemptionResponse(redemption), nil
+}
+
+// newCart builds the cart a voucher is checked against. When line items are
+// sent without an amount or item count, those are derived from the lines.
+// Carts without a currency are in DefaultCurrency.
+func newCart(amount models.Money, currency string, itemCount int, items []dto.CartItem) models.Cart {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+
+	cart := models.Cart{
+		Amount:    amount,
+		Currency:  currency,
+		ItemCount: itemCount,
+		Lines:     make([]models.CartLine, len(items)),
+	}
+
+	var linesAmount models.Money
+	linesCount := 0
+	for i, item := range items {
+		cart.Lines[i] = models.CartLine{
+			SKU:       strings.TrimSpace(item.SKU),
+			Category:  strings.TrimSpace(item.Category),
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+		linesAmount += cart.Lines[i].Subtotal()
+		linesCount += item.Quantity
+	}
+
+	if cart.Amount == 0 {
+		cart.Amount = linesAmount
+	}
+	if cart.ItemCount == 0 {
+		cart.ItemCount = linesCount
+	}
+	return cart
+}
+
+func toRedemptionResponse(redemption *models.Redemption) *dto.RedemptionResponse {
+	response := &dto.RedemptionResponse{
+		ID:             redemption.ID,
+		VoucherID:      redemption.VoucherID,
+		VoucherCode:    redemption.VoucherCode,
+		CustomerRef:    redemption.CustomerRef,
+		OrderRef:       redemption.OrderRef,
+		OrderAmount:    redemption.OrderAmount,
+		DiscountAmount: redemption.DiscountAmount,
+		Currency:       redemption.Currency,
+		Status:         redemption.Status,
+		ReversalReason: redemption.ReversalReason,
+	}
+	if redemption.ReservedAt != nil {
+		response.ReservedAt = utils.NewReadableDateTime(*redemption.ReservedAt)
+	}
+	if redemption.ExpiresAt != nil {
+		response.ExpiresAt = utils.NewReadableDateTime(*redemption.ExpiresAt)
+	}
+	if redemption.RedeemedAt != nil {
+		response.RedeemedAt = utils.NewReadableTime(*redemption.RedeemedAt)
+	}
+	if redemption.ReleasedAt != nil {
+		response.ReleasedAt = utils.NewReadableTime(*redemption.ReleasedAt)
+	}
+	if redemption.ReversedAt != nil {
+		response.ReversedAt = utils.NewReadableTime(*redemption.ReversedAt)
+	}
+	return response
+}
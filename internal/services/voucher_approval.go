@@ -0,0 +1,297 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/repository"
+	"github.com/rifqi142/indico-be/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrChangeRequestNotFound = errors.New("change request not found")
+	ErrChangeNotPending      = errors.New("change request has already been reviewed")
+	ErrSelfReview            = errors.New("a change request must be reviewed by a different user than the one who made it")
+)
+
+// ApprovalPolicy holds the thresholds above which a voucher create or update
+// needs a second user's approval. A zero threshold is never crossed.
+type ApprovalPolicy struct {
+	PercentDiscount models.Decimal
+	MaxUsage        int
+}
+
+// Check lists the thresholds the voucher crosses. For an update, before is
+// the voucher as stored, and a threshold only counts when the update raises
+// that value, so unrelated edits of an approved voucher go straight through.
+func (p ApprovalPolicy) Check(before, after *models.Voucher) []string {
+	var reasons []string
+
+	if p.PercentDiscount > 0 && !after.IsFixedDiscount() && after.Discount > p.PercentDiscount &&
+		(before == nil || before.IsFixedDiscount() || after.Discount > before.Discount) {
+		reasons = append(reasons, fmt.Sprintf("discount %s%% is above the %s%% approval threshold", after.Discount, p.PercentDiscount))
+	}
+	if p.MaxUsage > 0 && after.MaxUsage > p.MaxUsage &&
+		(before == nil || after.MaxUsage > before.MaxUsage) {
+		reasons = append(reasons, fmt.Sprintf("max_usage %d is above the %d approval threshold", after.MaxUsage, p.MaxUsage))
+	}
+
+	return reasons
+}
+
+func (s *voucherService) submitChange(action string, voucherID *uint, req interface{}, reasons []string, actor Actor) (*dto.ChangeRequestResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &models.VoucherChangeRequest{
+		VoucherID:   voucherID,
+		Action:      action,
+		Payload:     string(payload),
+		Reasons:     strings.Join(reasons, "\n"),
+		Status:      models.ChangeStatusPending,
+		RequestedBy: actor.Username,
+	}
+	if err := s.changeRepo.Create(change); err != nil {
+		return nil, err
+	}
+
+	return toChangeRequestResponse(change), nil
+}
+
+func (s *voucherService) GetChangeRequestByID(id uint) (*dto.ChangeRequestResponse, error) {
+	change, err := s.changeRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChangeRequestNotFound
+		}
+		return nil, err
+	}
+
+	return toChangeRequestResponse(change), nil
+}
+
+func (s *voucherService) GetAllChangeRequests(query dto.ChangeRequestListQuery) (*dto.ChangeRequestListResponse, error) {
+	changes, total, err := s.changeRepo.FindAll(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+
+	changeResponses := make([]dto.ChangeRequestResponse, len(changes))
+	for i, change := range changes {
+		changeResponses[i] = *toChangeRequestResponse(&change)
+	}
+
+	return &dto.ChangeRequestListResponse{
+		Data: changeResponses,
+		Pagination: dto.PaginationMeta{
+			CurrentPage: page,
+			PageSize:    pageSize,
+			TotalPages:  totalPages,
+			TotalItems:  total,
+		},
+	}, nil
+}
+
+// ApproveChangeRequest applies the stored create or update and marks the
+// request approved in one transaction. The change is validated again
+// against the current data, so an approval that no longer applies (e.g. the
+// code was taken in the meantime) fails and leaves the request pending.
+func (s *voucherService) ApproveChangeRequest(id uint, reviewer Actor, req dto.ReviewChangeRequest) (*dto.ChangeRequestResponse, error) {
+	var change *models.VoucherChangeRequest
+
+	err := s.changeRepo.Transaction(func(tx *gorm.DB) error {
+		txChanges := s.changeRepo.WithTx(tx)
+
+		locked, err := lockChangeForReview(txChanges, id, reviewer.Username)
+		if err != nil {
+			return err
+		}
+
+		voucherID, err := s.applyChange(s.repo.WithTx(tx), locked, reviewer)
+		if err != nil {
+			return err
+		}
+
+		locked.VoucherID = &voucherID
+		locked.Approve(reviewer.Username, req.Note, time.Now())
+		if err := txChanges.Update(locked); err != nil {
+			return err
+		}
+
+		change = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toChangeRequestResponse(change)
+	response.Voucher, err = s.GetVoucherByID(*change.VoucherID)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *voucherService) RejectChangeRequest(id uint, reviewer Actor, req dto.ReviewChangeRequest) (*dto.ChangeRequestResponse, error) {
+	var change *models.VoucherChangeRequest
+
+	err := s.changeRepo.Transaction(func(tx *gorm.DB) error {
+		txChanges := s.changeRepo.WithTx(tx)
+
+		locked, err := lockChangeForReview(txChanges, id, reviewer.Username)
+		if err != nil {
+			return err
+		}
+
+		locked.Reject(reviewer.Username, req.Note, time.Now())
+		if err := txChanges.Update(locked); err != nil {
+			return err
+		}
+
+		change = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toChangeRequestResponse(change), nil
+}
+
+// applyChange replays the stored request through the normal create or update
+// path, skipping the approval check, and returns the voucher's ID. The audit
+// entry names the reviewer as actor and points at the change request.
+func (s *voucherService) applyChange(repo repository.VoucherRepository, change *models.VoucherChangeRequest, reviewer Actor) (uint, error) {
+	switch change.Action {
+	case models.ChangeActionCreate:
+		var req dto.CreateVoucherRequest
+		if err := json.Unmarshal([]byte(change.Payload), &req); err != nil {
+			return 0, err
+		}
+
+		voucher, err := s.buildVoucher(req)
+		if err != nil {
+			return 0, err
+		}
+		entry := newAuditEntry(reviewer, models.AuditActionCreate, nil, voucher)
+		entry.ChangeRequestID = &change.ID
+		if err := s.createVoucher(repo, voucher, entry); err != nil {
+			return 0, err
+		}
+		return voucher.ID, nil
+
+	case models.ChangeActionUpdate:
+		var req dto.UpdateVoucherRequest
+		if err := json.Unmarshal([]byte(change.Payload), &req); err != nil {
+			return 0, err
+		}
+
+		voucher, err := lockChangedVoucher(repo, change.VoucherID)
+		if err != nil {
+			return 0, err
+		}
+		before := *voucher
+		if err := s.applyUpdate(voucher, req); err != nil {
+			return 0, err
+		}
+		entry := newAuditEntry(reviewer, models.AuditActionUpdate, &before, voucher)
+		entry.ChangeRequestID = &change.ID
+		if err := s.saveUpdate(repo, voucher, req.Targets != nil, req.Schedules != nil, entry); err != nil {
+			return 0, err
+		}
+		return voucher.ID, nil
+
+	case models.ChangeActionPatch:
+		var patch dto.VoucherPatch
+		if err := json.Unmarshal([]byte(change.Payload), &patch); err != nil {
+			return 0, err
+		}
+
+		// The patch is applied to the voucher as it is now, so it may no
+		// longer apply, e.g. when a JSON Patch test fails
+		voucher, err := lockChangedVoucher(repo, change.VoucherID)
+		if err != nil {
+			return 0, err
+		}
+		before := *voucher
+		if err := s.applyPatch(voucher, patch); err != nil {
+			return 0, err
+		}
+		entry := newAuditEntry(reviewer, models.AuditActionUpdate, &before, voucher)
+		entry.ChangeRequestID = &change.ID
+		if err := s.saveUpdate(repo, voucher, true, true, entry); err != nil {
+			return 0, err
+		}
+		return voucher.ID, nil
+	}
+
+	return 0, fmt.Errorf("unknown change action %q", change.Action)
+}
+
+// lockChangedVoucher locks the voucher a change applies to, then loads it with
+// targets and schedules for the audit diff.
+func lockChangedVoucher(repo repository.VoucherRepository, id *uint) (*models.Voucher, error) {
+	if id == nil {
+		return nil, ErrVoucherNotFound
+	}
+	return lockVoucher(repo, *id)
+}
+
+func lockChangeForReview(repo repository.ChangeRequestRepository, id uint, reviewer string) (*models.VoucherChangeRequest, error) {
+	change, err := repo.FindByIDForUpdate(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChangeRequestNotFound
+		}
+		return nil, err
+	}
+
+	if !change.IsPending() {
+		return nil, ErrChangeNotPending
+	}
+	if reviewer == "" || reviewer == change.RequestedBy {
+		return nil, ErrSelfReview
+	}
+	return change, nil
+}
+
+func toChangeRequestResponse(change *models.VoucherChangeRequest) *dto.ChangeRequestResponse {
+	response := &dto.ChangeRequestResponse{
+		ID:          change.ID,
+		VoucherID:   change.VoucherID,
+		Action:      change.Action,
+		Status:      change.Status,
+		Reasons:     change.ReasonList(),
+		Payload:     json.RawMessage(change.Payload),
+		RequestedBy: change.RequestedBy,
+		ReviewedBy:  change.ReviewedBy,
+		ReviewNote:  change.ReviewNote,
+		CreatedAt:   utils.NewReadableTime(change.CreatedAt),
+		UpdatedAt:   utils.NewReadableTime(change.UpdatedAt),
+	}
+	if change.ReviewedAt != nil {
+		response.ReviewedAt = utils.NewReadableTime(*change.ReviewedAt)
+	}
+	return response
+}
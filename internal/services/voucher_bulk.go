@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	bulkActionActivate       = "activate"
+	bulkActionDeactivate     = "deactivate"
+	bulkActionDelete         = "delete"
+	bulkActionExtendValidity = "extend_validity"
+	bulkActionChangeDiscount = "change_discount"
+
+	bulkItemUpdated    = "updated"
+	bulkItemSkipped    = "skipped"
+	bulkItemFailed     = "failed"
+	bulkItemRolledBack = "rolled_back"
+
+	// maxBulkItems caps how many vouchers one bulk request may touch.
+	maxBulkItems = 1000
+)
+
+var (
+	ErrBulkTargets  = errors.New("choose vouchers either by ids or by filter, not both and not neither")
+	ErrBulkTooLarge = fmt.Errorf("a bulk request may change at most %d vouchers, narrow the filter", maxBulkItems)
+	ErrBulkParams   = errors.New("missing or invalid parameter for this bulk action")
+
+	// errBulkAborted rolls back an atomic batch in which an item failed.
+	errBulkAborted = errors.New("bulk batch aborted")
+)
+
+// BulkUpdateVouchers applies the action to every voucher in req.IDs or, when
+// no IDs are given, every voucher matching filter. The batch runs in one
+// transaction and each voucher in a savepoint of its own, so a failed item
+// is undone alone. With req.Atomic a single failure undoes the whole batch.
+func (s *voucherService) BulkUpdateVouchers(req dto.BulkVoucherRequest, filter dto.VoucherFilter, actor Actor) (*dto.BulkVoucherResponse, error) {
+	if err := checkBulkParams(req); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.bulkTargets(req, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.BulkVoucherResponse{
+		Action:  req.Action,
+		Matched: len(ids),
+		Results: make([]dto.BulkItemResult, 0, len(ids)),
+	}
+
+	err = s.repo.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, id := range ids {
+			result := dto.BulkItemResult{ID: id, Status: bulkItemUpdated}
+
+			err := tx.Transaction(func(itemTx *gorm.DB) error {
+				code, changed, err := s.bulkApply(itemTx, id, req, now, actor)
+				result.Code = code
+				if err == nil && !changed {
+					result.Status = bulkItemSkipped
+				}
+				return err
+			})
+			if err != nil {
+				result.Status = bulkItemFailed
+				result.Error = err.Error()
+			}
+
+			response.Results = append(response.Results, result)
+		}
+
+		if req.Atomic && bulkHasFailures(response.Results) {
+			return errBulkAborted
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkAborted) {
+		return nil, err
+	}
+
+	response.RolledBack = err != nil
+	for i := range response.Results {
+		result := &response.Results[i]
+		if response.RolledBack && result.Status == bulkItemUpdated {
+			result.Status = bulkItemRolledBack
+		}
+		switch result.Status {
+		case bulkItemUpdated:
+			response.Updated++
+		case bulkItemSkipped:
+			response.Skipped++
+		case bulkItemFailed:
+			response.Failed++
+		}
+	}
+
+	return response, nil
+}
+
+// bulkTargets resolves the IDs the batch applies to, keeping the order and
+// dropping repeats of explicit IDs.
+func (s *voucherService) bulkTargets(req dto.BulkVoucherRequest, filter dto.VoucherFilter) ([]uint, error) {
+	// An empty filter cannot select every voucher by accident
+	if (len(req.IDs) > 0) == !filter.IsEmpty() {
+		return nil, ErrBulkTargets
+	}
+
+	if len(req.IDs) > 0 {
+		seen := make(map[uint]bool, len(req.IDs))
+		ids := make([]uint, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	ids, err := s.repo.FindIDs(filter, maxBulkItems+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > maxBulkItems {
+		return nil, ErrBulkTooLarge
+	}
+	return ids, nil
+}
+
+// bulkApply applies the action to one voucher under a row lock and writes
+// its audit entry. It returns the voucher's code and whether anything changed.
+func (s *voucherService) bulkApply(tx *gorm.DB, id uint, req dto.BulkVoucherRequest, now time.Time, actor Actor) (string, bool, error) {
+	repo := s.repo.WithTx(tx)
+	audit := s.auditRepo.WithTx(tx)
+
+	// Lock first, then load with targets and schedules for the audit diff
+	if _, err := repo.FindByIDForUpdate(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", false, ErrVoucherNotFound
+		}
+		return "", false, err
+	}
+	voucher, err := repo.FindByID(id)
+	if err != nil {
+		return "", false, err
+	}
+	before := *voucher
+
+	switch req.Action {
+	case bulkActionActivate, bulkActionDeactivate:
+		transition := models.TransitionPause
+		target := models.VoucherStatusPaused
+		if req.Action == bulkActionActivate {
+			transition = models.TransitionResume
+			if voucher.Status == models.VoucherStatusDraft {
+				transition = models.TransitionPublish
+			}
+			target = models.VoucherStatusActive
+		}
+
+		if voucher.Status == target {
+			return voucher.Code, false, nil
+		}
+		if !voucher.Transition(transition, now) {
+			return voucher.Code, false, fmt.Errorf("%w: cannot %s a voucher that is %s", ErrInvalidTransition, transition, voucher.State(now))
+		}
+		if err := repo.UpdateStatus(voucher); err != nil {
+			return voucher.Code, false, err
+		}
+		return voucher.Code, true, audit.Create([]models.AuditLog{newAuditEntry(actor, string(transition), &before, voucher)})
+
+	case bulkActionDelete:
+		if err := repo.Delete(id); err != nil {
+			return voucher.Code, false, err
+		}
+		return voucher.Code, true, audit.Create([]models.AuditLog{newAuditEntry(actor, models.AuditActionDelete, voucher, nil)})
+
+	case bulkActionExtendValidity:
+		if req.ValidUntil.Equal(voucher.ValidUntil) {
+			return voucher.Code, false, nil
+		}
+		if req.ValidUntil.Before(voucher.ValidUntil) {
+			return voucher.Code, false, errors.New("valid_until is earlier than the voucher's current end, extending cannot shorten it")
+		}
+		voucher.ValidUntil = req.ValidUntil
+
+	case bulkActionChangeDiscount:
+		// The same number is a percentage on one type and an amount on the other
+		if voucher.DiscountType != req.DiscountType {
+			return voucher.Code, false, fmt.Errorf("voucher has a %s discount, not %s", voucher.DiscountType, req.DiscountType)
+		}
+		if req.Discount == voucher.Discount {
+			return voucher.Code, false, nil
+		}
+		voucher.Discount = req.Discount
+		if err := normalizeDiscount(voucher); err != nil {
+			return voucher.Code, false, err
+		}
+		if reasons := s.approval.Check(&before, voucher); len(reasons) > 0 {
+			return voucher.Code, false, fmt.Errorf("needs approval, change it through PUT /vouchers/%d: %s", id, strings.Join(reasons, "; "))
+		}
+	}
+
+	if err := repo.Update(voucher); err != nil {
+		return voucher.Code, false, err
+	}
+	return voucher.Code, true, audit.Create([]models.AuditLog{newAuditEntry(actor, models.AuditActionUpdate, &before, voucher)})
+}
+
+func checkBulkParams(req dto.BulkVoucherRequest) error {
+	switch req.Action {
+	case bulkActionExtendValidity:
+		if req.ValidUntil.IsZero() {
+			return fmt.Errorf("%w: extend_validity needs valid_until", ErrBulkParams)
+		}
+	case bulkActionChangeDiscount:
+		if req.Discount <= 0 {
+			return fmt.Errorf("%w: change_discount needs a discount greater than 0", ErrBulkParams)
+		}
+		if req.DiscountType == "" {
+			return fmt.Errorf("%w: change_discount needs the discount_type of the vouchers to change", ErrBulkParams)
+		}
+	}
+	return nil
+}
+
+func bulkHasFailures(results []dto.BulkItemResult) bool {
+	for _, result := range results {
+		if result.Status == bulkItemFailed {
+			return true
+		}
+	}
+	return false
+}
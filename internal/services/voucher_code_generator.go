@@ -0,0 +1,225 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/repository"
+	"github.com/rifqi142/indico-be/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultCodeCharset leaves out O, 0, I and 1, which are easily confused.
+	DefaultCodeCharset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	// ambiguousCodeChars are also dropped from custom charsets.
+	ambiguousCodeChars = "O0I1"
+
+	defaultCodeLength = 8
+	minCodeLength     = 3
+	maxCodeLength     = 50
+
+	codeInsertBatchSize = 1000
+	codeInsertAttempts  = 3
+)
+
+var (
+	ErrCodeSpaceTooSmall    = errors.New("code pattern cannot produce that many unique codes, use a longer length or a larger charset")
+	ErrCodeGenerationFailed = errors.New("could not generate unique codes, please try again")
+)
+
+// GenerateCodes creates req.Count vouchers with random unique codes, each a
+// copy of the template voucher. Codes taken by existing vouchers are redrawn
+// before inserting, and the whole set is retried if another request claims
+// one of them in the meantime. Every voucher gets its own audit entry.
+func (s *voucherService) GenerateCodes(templateID uint, req dto.GenerateCodesRequest, actor Actor) (*dto.GenerateCodesResponse, error) {
+	template, err := s.repo.FindByID(templateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVoucherNotFound
+		}
+		return nil, err
+	}
+
+	length := req.Length
+	if length == 0 {
+		length = defaultCodeLength
+	}
+	prefix := models.NormalizeVoucherCode(req.Prefix)
+	suffix := models.NormalizeVoucherCode(req.Suffix)
+	if len(prefix)+length+len(suffix) > maxCodeLength {
+		return nil, fmt.Errorf("generated codes must be at most %d characters", maxCodeLength)
+	}
+
+	charset := codeCharset(req.Charset)
+	if len(charset) < 2 {
+		return nil, errors.New("charset must contain at least 2 unambiguous characters")
+	}
+	if !codeSpaceFits(len(charset), length, req.Count) {
+		return nil, ErrCodeSpaceTooSmall
+	}
+
+	generator, err := utils.NewRandomStringGenerator(charset)
+	if err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.VoucherStatusDraft
+	}
+
+	for attempt := 0; attempt < codeInsertAttempts; attempt++ {
+		codes, err := s.drawUniqueCodes(generator, prefix, suffix, length, req.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		vouchers := make([]models.Voucher, len(codes))
+		for i, code := range codes {
+			vouchers[i] = copyVoucherTemplate(template, code, status)
+		}
+
+		err = s.repo.Transaction(func(tx *gorm.DB) error {
+			if err := s.repo.WithTx(tx).CreateInBatches(vouchers, codeInsertBatchSize); err != nil {
+				return err
+			}
+			entries := make([]models.AuditLog, len(vouchers))
+			for i := range vouchers {
+				entries[i] = newAuditEntry(actor, models.AuditActionGenerate, nil, &vouchers[i])
+			}
+			return s.auditRepo.WithTx(tx).Create(entries)
+		})
+		if err == nil {
+			return &dto.GenerateCodesResponse{
+				TemplateID: template.ID,
+				Count:      len(codes),
+				Codes:      codes,
+			}, nil
+		}
+		if !repository.IsCodeConflictError(err) {
+			return nil, err
+		}
+	}
+
+	return nil, ErrCodeGenerationFailed
+}
+
+// drawUniqueCodes draws count distinct codes that no voucher uses yet. Codes
+// found to be taken are remembered and replaced in the next round.
+func (s *voucherService) drawUniqueCodes(generator *utils.RandomStringGenerator, prefix, suffix string, length, count int) ([]string, error) {
+	seen := make(map[string]bool, count)
+	codes := make([]string, 0, count)
+
+	// Bounds the work when most of the code space is already taken
+	maxDraws := 20*count + 100
+	draws := 0
+
+	for len(codes) < count {
+		var batch []string
+		for len(codes)+len(batch) < count {
+			if draws >= maxDraws {
+				return nil, ErrCodeSpaceTooSmall
+			}
+			draws++
+
+			random, err := generator.Next(length)
+			if err != nil {
+				return nil, err
+			}
+			code := prefix + random + suffix
+			if seen[code] {
+				continue
+			}
+			seen[code] = true
+			batch = append(batch, code)
+		}
+
+		taken, err := s.repo.FindExistingCodes(batch)
+		if err != nil {
+			return nil, err
+		}
+		takenSet := make(map[string]bool, len(taken))
+		for _, code := range taken {
+			takenSet[code] = true
+		}
+		for _, code := range batch {
+			if !takenSet[code] {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	return codes, nil
+}
+
+// codeCharset uppercases a custom charset and removes ambiguous and repeated
+// characters. An empty charset means DefaultCodeCharset.
+func codeCharset(custom string) string {
+	if custom == "" {
+		return DefaultCodeCharset
+	}
+
+	var b strings.Builder
+	for _, r := range models.NormalizeVoucherCode(custom) {
+		if strings.ContainsRune(ambiguousCodeChars, r) || strings.ContainsRune(b.String(), r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// codeSpaceFits reports whether charsetSize^length is at least twice count,
+// which keeps random collisions between new codes rare.
+func codeSpaceFits(charsetSize, length, count int) bool {
+	needed := 2 * count
+	space := 1
+	for i := 0; i < length; i++ {
+		space *= charsetSize
+		if space >= needed {
+			return true
+		}
+	}
+	return space >= needed
+}
+
+// copyVoucherTemplate returns a new, unused voucher with the template's
+// settings, targets and schedules under the given code and status. The
+// template's own status and version are not carried over.
+func copyVoucherTemplate(template *models.Voucher, code, status string) models.Voucher {
+	voucher := *template
+	voucher.ID = 0
+	voucher.Code = code
+	voucher.Status = status
+	voucher.PausedByCampaign = false
+	voucher.Version = 1
+	voucher.UsedCount = 0
+	voucher.ReservedCount = 0
+	voucher.CreatedAt = time.Time{}
+	voucher.UpdatedAt = time.Time{}
+	voucher.DeletedAt = gorm.DeletedAt{}
+	voucher.Campaign = nil
+
+	voucher.Targets = make([]models.VoucherTarget, len(template.Targets))
+	for i, target := range template.Targets {
+		target.ID = 0
+		target.VoucherID = 0
+		target.CreatedAt = time.Time{}
+		voucher.Targets[i] = target
+	}
+
+	voucher.Schedules = make([]models.VoucherSchedule, len(template.Schedules))
+	for i, schedule := range template.Schedules {
+		schedule.ID = 0
+		schedule.VoucherID = 0
+		schedule.CreatedAt = time.Time{}
+		voucher.Schedules[i] = schedule
+	}
+	return voucher
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/repository"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// voucherCursor is the decoded form of the opaque cursor the voucher list
+// hands out: the key of the last voucher on a page and a fingerprint of the
+// filters and sort it was listed with, so it cannot be reused with others.
+type voucherCursor struct {
+	Value  json.RawMessage `json:"v,omitempty"`
+	ID     uint            `json:"id"`
+	Filter string          `json:"f"`
+}
+
+// encodeVoucherCursor gives the cursor of the page that follows voucher.
+func encodeVoucherCursor(voucher *models.Voucher, query dto.VoucherListQuery) (string, error) {
+	cursor := voucherCursor{ID: voucher.ID, Filter: listFingerprint(query)}
+
+	sortBy, _ := query.Sort()
+	if value := voucherSortValue(voucher, sortBy); value != nil {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		cursor.Value = data
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeVoucherCursor turns a cursor back into the key to list after. It
+// fails with ErrInvalidCursor when the cursor is malformed or was handed out
+// for other filters or another sort.
+func decodeVoucherCursor(encoded string, query dto.VoucherListQuery) (*repository.VoucherKey, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var cursor voucherCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if cursor.Filter != listFingerprint(query) {
+		return nil, fmt.Errorf("%w: send the same filters and sort as for the first page", ErrInvalidCursor)
+	}
+
+	key := &repository.VoucherKey{ID: cursor.ID}
+	sortBy, _ := query.Sort()
+	switch sortBy {
+	case "code", "name":
+		var value string
+		err = json.Unmarshal(cursor.Value, &value)
+		key.Value = value
+	case "discount":
+		var value int64
+		err = json.Unmarshal(cursor.Value, &value)
+		key.Value = value
+	case "created_at":
+		var value time.Time
+		err = json.Unmarshal(cursor.Value, &value)
+		key.Value = value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return key, nil
+}
+
+// voucherSortValue is the voucher's value in the sort column, in the form it
+// is compared in SQL. The ID needs none, it is always part of the key.
+func voucherSortValue(voucher *models.Voucher, sortBy string) interface{} {
+	switch sortBy {
+	case "code":
+		return voucher.Code
+	case "name":
+		return voucher.Name
+	case "discount":
+		return int64(voucher.Discount)
+	case "created_at":
+		return voucher.CreatedAt
+	}
+	return nil
+}
+
+// listFingerprint hashes the filters and sort of a list query, leaving out
+// the paging fields.
+func listFingerprint(query dto.VoucherListQuery) string {
+	query.SortBy, query.SortOrder = query.Sort()
+	query.Page = 0
+	query.PageSize = 0
+	query.Cursor = ""
+	query.IncludeTotal = nil
+
+	data, _ := json.Marshal(query)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
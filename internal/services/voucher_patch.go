@@ -0,0 +1,148 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/utils"
+	"gorm.io/gorm"
+)
+
+// PatchVoucher applies a merge patch or JSON Patch to the voucher's document
+// and saves the result, or files it as a pending change request when it
+// raises a value above an approval threshold. The patched document is
+// validated as a whole, so rules across fields hold for the merged result.
+func (s *voucherService) PatchVoucher(id uint, patch dto.VoucherPatch, ifMatch string, actor Actor) (*dto.VoucherResponse, *dto.ChangeRequestResponse, error) {
+	voucher, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrVoucherNotFound
+		}
+		return nil, nil, err
+	}
+	if err := checkIfMatch(voucher, ifMatch); err != nil {
+		return nil, nil, err
+	}
+
+	before := *voucher
+	if err := s.applyPatch(voucher, patch); err != nil {
+		return nil, nil, err
+	}
+
+	if reasons := s.approval.Check(&before, voucher); len(reasons) > 0 {
+		change, err := s.submitChange(models.ChangeActionPatch, &voucher.ID, patch, reasons, actor)
+		return nil, change, err
+	}
+
+	if err := s.saveUpdate(s.repo, voucher, true, true, newAuditEntry(actor, models.AuditActionUpdate, &before, voucher)); err != nil {
+		return nil, nil, err
+	}
+
+	return toVoucherResponse(voucher), nil, nil
+}
+
+// applyPatch patches the voucher's document, validates the result and copies
+// it back onto the voucher, without saving it.
+func (s *voucherService) applyPatch(voucher *models.Voucher, patch dto.VoucherPatch) error {
+	current, err := json.Marshal(toVoucherDocument(voucher))
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch patch.Format {
+	case dto.PatchFormatMerge:
+		patched, err = utils.MergePatch(current, patch.Patch)
+	case dto.PatchFormatJSON:
+		patched, err = utils.ApplyJSONPatch(current, patch.Patch)
+	default:
+		return fmt.Errorf("%w: unknown patch format %q", utils.ErrInvalidPatch, patch.Format)
+	}
+	if err != nil {
+		// Already utils.ErrInvalidPatch, or ErrPatchTestFailed for a 409
+		return err
+	}
+
+	// Fields outside the document, such as status or used_count, are rejected
+	var document dto.VoucherDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("%w: %v", utils.ErrInvalidPatch, err)
+	}
+	if err := binding.Validator.ValidateStruct(&document); err != nil {
+		return fmt.Errorf("%w: %v", utils.ErrInvalidPatch, err)
+	}
+
+	voucher.Code = document.Code
+	voucher.Name = document.Name
+	voucher.Description = document.Description
+	voucher.Discount = document.Discount
+	voucher.DiscountType = document.DiscountType
+	voucher.Currency = document.Currency
+	voucher.MaxDiscount = document.MaxDiscount
+	voucher.MinOrderAmount = document.MinOrderAmount
+	voucher.MinItemCount = document.MinItemCount
+	voucher.MaxUsage = document.MaxUsage
+	voucher.MaxUsagePerCustomer = document.MaxUsagePerCustomer
+	voucher.ValidFrom = document.ValidFrom
+	voucher.ValidUntil = document.ValidUntil
+	voucher.StackingMode = document.StackingMode
+	voucher.ExclusivityGroup = document.ExclusivityGroup
+	voucher.Targets = toVoucherTargets(document.Targets)
+	voucher.Schedules = toVoucherSchedules(document.Schedules)
+
+	if err := normalizeVoucher(voucher); err != nil {
+		return err
+	}
+
+	campaign, err := s.assignCampaign(voucher, document.CampaignID)
+	if err != nil {
+		return err
+	}
+	voucher.Campaign = campaign
+	return nil
+}
+
+// toVoucherDocument gives the voucher's patchable fields in the shape of the
+// create request, which is what patches are written against.
+func toVoucherDocument(voucher *models.Voucher) dto.VoucherDocument {
+	targets := make([]dto.VoucherTargetRequest, len(voucher.Targets))
+	for i, target := range voucher.Targets {
+		targets[i] = dto.VoucherTargetRequest{Type: target.TargetType, Value: target.Value, Mode: target.Mode}
+	}
+	schedules := make([]dto.VoucherScheduleRequest, len(voucher.Schedules))
+	for i, schedule := range voucher.Schedules {
+		schedules[i] = dto.VoucherScheduleRequest{
+			Days:      schedule.DayList(),
+			StartTime: schedule.StartTime,
+			EndTime:   schedule.EndTime,
+		}
+	}
+
+	return dto.VoucherDocument{
+		Code:                voucher.Code,
+		Name:                voucher.Name,
+		Description:         voucher.Description,
+		Discount:            voucher.Discount,
+		DiscountType:        voucher.DiscountType,
+		Currency:            voucher.Currency,
+		MaxDiscount:         voucher.MaxDiscount,
+		MinOrderAmount:      voucher.MinOrderAmount,
+		MinItemCount:        voucher.MinItemCount,
+		MaxUsage:            voucher.MaxUsage,
+		MaxUsagePerCustomer: voucher.MaxUsagePerCustomer,
+		ValidFrom:           voucher.ValidFrom,
+		ValidUntil:          voucher.ValidUntil,
+		StackingMode:        voucher.StackingMode,
+		ExclusivityGroup:    voucher.ExclusivityGroup,
+		CampaignID:          voucher.CampaignID,
+		Targets:             targets,
+		Schedules:           schedules,
+	}
+}
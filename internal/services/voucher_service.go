@@ -17,71 +17,186 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrVoucherNotFound        = errors.New("voucher not found")
+	ErrInvalidPercentDiscount = errors.New("percentage discount must be between 0 and 100")
+	ErrInvalidFixedDiscount   = errors.New("fixed discount must be greater than 0")
+	ErrInvalidTransition      = errors.New("voucher cannot make this transition")
+	ErrVoucherCodeConflict    = errors.New("voucher code is already in use")
+	ErrVoucherModified        = errors.New("voucher has been modified since it was read")
+)
+
+// VoucherCodeConflictError names the code that is taken and, when known, the
+// live voucher that holds it.
+type VoucherCodeConflictError struct {
+	Code      string
+	VoucherID uint
+}
+
+func (e *VoucherCodeConflictError) Error() string {
+	if e.VoucherID > 0 {
+		return fmt.Sprintf("voucher code %q is already used by voucher %d", e.Code, e.VoucherID)
+	}
+	return fmt.Sprintf("voucher code %q is already in use", e.Code)
+}
+
+func (e *VoucherCodeConflictError) Unwrap() error {
+	return ErrVoucherCodeConflict
+}
+
+// codeConflict reports a write rejected by the live code index as a
+// VoucherCodeConflictError and passes any other error through.
+func codeConflict(err error, code string) error {
+	if repository.IsCodeConflictError(err) {
+		return &VoucherCodeConflictError{Code: code}
+	}
+	return err
+}
+
 type VoucherService interface {
-	CreateVoucher(req dto.CreateVoucherRequest) (*dto.VoucherResponse, error)
+	CreateVoucher(req dto.CreateVoucherRequest, actor Actor) (*dto.VoucherResponse, *dto.ChangeRequestResponse, error)
 	GetVoucherByID(id uint) (*dto.VoucherResponse, error)
 	GetAllVouchers(query dto.VoucherListQuery) (*dto.VoucherListResponse, error)
-	UpdateVoucher(id uint, req dto.UpdateVoucherRequest) (*dto.VoucherResponse, error)
-	DeleteVoucher(id uint) error
-	ImportFromCSV(reader io.Reader) (*dto.CSVUploadResponse, error)
-	ExportToCSV() ([][]string, error)
+	UpdateVoucher(id uint, req dto.UpdateVoucherRequest, ifMatch string, actor Actor) (*dto.VoucherResponse, *dto.ChangeRequestResponse, error)
+	PatchVoucher(id uint, patch dto.VoucherPatch, ifMatch string, actor Actor) (*dto.VoucherResponse, *dto.ChangeRequestResponse, error)
+	DeleteVoucher(id uint, ifMatch string, actor Actor) error
+	TransitionVoucher(id uint, transition models.VoucherTransition, actor Actor) (*dto.VoucherResponse, error)
+	GenerateCodes(templateID uint, req dto.GenerateCodesRequest, actor Actor) (*dto.GenerateCodesResponse, error)
+	ImportFromCSV(reader io.Reader, actor Actor) (*dto.CSVUploadResponse, error)
+	ExportToCSV(filter dto.VoucherFilter) ([][]string, error)
+	GetChangeRequestByID(id uint) (*dto.ChangeRequestResponse, error)
+	GetAllChangeRequests(query dto.ChangeRequestListQuery) (*dto.ChangeRequestListResponse, error)
+	ApproveChangeRequest(id uint, reviewer Actor, req dto.ReviewChangeRequest) (*dto.ChangeRequestResponse, error)
+	RejectChangeRequest(id uint, reviewer Actor, req dto.ReviewChangeRequest) (*dto.ChangeRequestResponse, error)
+	GetTrash(query dto.TrashListQuery) (*dto.TrashListResponse, error)
+	RestoreVoucher(id uint, req dto.RestoreVoucherRequest, actor Actor) (*dto.VoucherResponse, error)
+	PurgeVoucher(id uint, actor Actor) error
+	PurgeExpiredTrash() (int, error)
+	BulkUpdateVouchers(req dto.BulkVoucherRequest, filter dto.VoucherFilter, actor Actor) (*dto.BulkVoucherResponse, error)
 }
 
 type voucherService struct {
-	repo repository.VoucherRepository
+	repo           repository.VoucherRepository
+	campaignRepo   repository.CampaignRepository
+	changeRepo     repository.ChangeRequestRepository
+	auditRepo      repository.AuditRepository
+	approval       ApprovalPolicy
+	trashRetention time.Duration
 }
 
-func NewVoucherService(repo repository.VoucherRepository) VoucherService {
-	return &voucherService{repo: repo}
+func NewVoucherService(
+	repo repository.VoucherRepository,
+	campaignRepo repository.CampaignRepository,
+	changeRepo repository.ChangeRequestRepository,
+	auditRepo repository.AuditRepository,
+	approval ApprovalPolicy,
+	trashRetention time.Duration,
+) VoucherService {
+	return &voucherService{
+		repo:           repo,
+		campaignRepo:   campaignRepo,
+		changeRepo:     changeRepo,
+		auditRepo:      auditRepo,
+		approval:       approval,
+		trashRetention: trashRetention,
+	}
 }
 
-func (s *voucherService) CreateVoucher(req dto.CreateVoucherRequest) (*dto.VoucherResponse, error) {
-	existing, _ := s.repo.FindByCode(req.Code)
-	if existing != nil {
-		return nil, errors.New("voucher code already exists")
+// CreateVoucher creates the voucher, or files it as a pending change request
+// when it crosses an approval threshold.
+func (s *voucherService) CreateVoucher(req dto.CreateVoucherRequest, actor Actor) (*dto.VoucherResponse, *dto.ChangeRequestResponse, error) {
+	voucher, err := s.buildVoucher(req)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	isActive := true
-	if req.IsActive != nil {
-		isActive = *req.IsActive
+	if reasons := s.approval.Check(nil, voucher); len(reasons) > 0 {
+		change, err := s.submitChange(models.ChangeActionCreate, nil, req, reasons, actor)
+		return nil, change, err
+	}
+
+	if err := s.createVoucher(s.repo, voucher, newAuditEntry(actor, models.AuditActionCreate, nil, voucher)); err != nil {
+		return nil, nil, err
 	}
 
+	return toVoucherResponse(voucher), nil, nil
+}
+
+// createVoucher inserts the voucher and its audit entry in one transaction.
+// The entry is built before the insert, so its voucher ID is filled in here.
+// Code uniqueness is left to the database, so concurrent creates of the same
+// code cannot both pass a lookup.
+func (s *voucherService) createVoucher(repo repository.VoucherRepository, voucher *models.Voucher, entry models.AuditLog) error {
+	return repo.Transaction(func(tx *gorm.DB) error {
+		if err := repo.WithTx(tx).Create(voucher); err != nil {
+			return codeConflict(err, voucher.Code)
+		}
+		entry.VoucherID = voucher.ID
+		return s.auditRepo.WithTx(tx).Create([]models.AuditLog{entry})
+	})
+}
+
+// buildVoucher turns a create request into a normalized voucher with its
+// campaign attached, without saving it.
+func (s *voucherService) buildVoucher(req dto.CreateVoucherRequest) (*models.Voucher, error) {
 	voucher := &models.Voucher{
-		Code:        req.Code,
-		Name:        req.Name,
-		Description: req.Description,
-		Discount:    req.Discount,
-		MaxUsage:    req.MaxUsage,
-		ValidFrom:   req.ValidFrom,
-		ValidUntil:  req.ValidUntil,
-		IsActive:    isActive,
+		Code:             req.Code,
+		Name:             req.Name,
+		Description:      req.Description,
+		Discount:         req.Discount,
+		DiscountType:     req.DiscountType,
+		Currency:         req.Currency,
+		MaxUsage:         req.MaxUsage,
+		ValidFrom:        req.ValidFrom,
+		ValidUntil:       req.ValidUntil,
+		Status:           req.Status,
+		StackingMode:     req.StackingMode,
+		ExclusivityGroup: strings.TrimSpace(req.ExclusivityGroup),
+		Targets:          toVoucherTargets(req.Targets),
+		Schedules:        toVoucherSchedules(req.Schedules),
+	}
+	if req.MaxDiscount != nil {
+		voucher.MaxDiscount = *req.MaxDiscount
+	}
+	if req.MinOrderAmount != nil {
+		voucher.MinOrderAmount = *req.MinOrderAmount
+	}
+	if req.MinItemCount != nil {
+		voucher.MinItemCount = *req.MinItemCount
+	}
+	if req.MaxUsagePerCustomer != nil {
+		voucher.MaxUsagePerCustomer = *req.MaxUsagePerCustomer
+	}
+
+	if err := normalizeVoucher(voucher); err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Create(voucher); err != nil {
+	campaign, err := s.assignCampaign(voucher, req.CampaignID)
+	if err != nil {
 		return nil, err
 	}
+	voucher.Campaign = campaign
 
-	return s.toVoucherResponse(voucher), nil
+	return voucher, nil
 }
 
 func (s *voucherService) GetVoucherByID(id uint) (*dto.VoucherResponse, error) {
 	voucher, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("voucher not found")
+			return nil, ErrVoucherNotFound
 		}
 		return nil, err
 	}
 
-	return s.toVoucherResponse(voucher), nil
+	return toVoucherResponse(voucher), nil
 }
 
+// GetAllVouchers lists one page of vouchers, found by page number or by the
+// cursor of the previous page. The total is only counted when asked for, and
+// by default only for pages found by number.
 func (s *voucherService) GetAllVouchers(query dto.VoucherListQuery) (*dto.VoucherListResponse, error) {
-	vouchers, total, err := s.repo.FindAll(query)
-	if err != nil {
-		return nil, err
-	}
-
 	page := 1
 	pageSize := 10
 	if query.Page > 0 {
@@ -91,39 +206,98 @@ func (s *voucherService) GetAllVouchers(query dto.VoucherListQuery) (*dto.Vouche
 		pageSize = query.PageSize
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+	var after *repository.VoucherKey
+	if query.Cursor != "" {
+		if query.Page > 0 {
+			return nil, fmt.Errorf("%w: page cannot be combined with cursor", ErrInvalidCursor)
+		}
+		key, err := decodeVoucherCursor(query.Cursor, query)
+		if err != nil {
+			return nil, err
+		}
+		after = key
+	}
+
+	// One voucher more than the page tells whether another page follows
+	vouchers, err := s.repo.FindAll(query, after, (page-1)*pageSize, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := dto.VoucherPaginationMeta{PageSize: pageSize}
+	if after == nil {
+		pagination.CurrentPage = page
+	}
+	if len(vouchers) > pageSize {
+		vouchers = vouchers[:pageSize]
+		pagination.HasMore = true
+		pagination.NextCursor, err = encodeVoucherCursor(&vouchers[pageSize-1], query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	includeTotal := after == nil
+	if query.IncludeTotal != nil {
+		includeTotal = *query.IncludeTotal
+	}
+	if includeTotal {
+		total, err := s.repo.Count(query.VoucherFilter)
+		if err != nil {
+			return nil, err
+		}
+		totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+		pagination.TotalItems = &total
+		pagination.TotalPages = &totalPages
+	}
 
 	voucherResponses := make([]dto.VoucherResponse, len(vouchers))
 	for i, voucher := range vouchers {
-		voucherResponses[i] = *s.toVoucherResponse(&voucher)
+		voucherResponses[i] = *toVoucherResponse(&voucher)
 	}
 
 	return &dto.VoucherListResponse{
-		Data: voucherResponses,
-		Pagination: dto.PaginationMeta{
-			CurrentPage: page,
-			PageSize:    pageSize,
-			TotalPages:  totalPages,
-			TotalItems:  total,
-		},
+		Data:       voucherResponses,
+		Pagination: pagination,
 	}, nil
 }
 
-func (s *voucherService) UpdateVoucher(id uint, req dto.UpdateVoucherRequest) (*dto.VoucherResponse, error) {
+// UpdateVoucher applies the update, or files it as a pending change request
+// when it raises a value above an approval threshold. ifMatch is the
+// client's If-Match header; see checkIfMatch.
+func (s *voucherService) UpdateVoucher(id uint, req dto.UpdateVoucherRequest, ifMatch string, actor Actor) (*dto.VoucherResponse, *dto.ChangeRequestResponse, error) {
 	voucher, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("voucher not found")
+			return nil, nil, ErrVoucherNotFound
 		}
-		return nil, err
+		return nil, nil, err
+	}
+	if err := checkIfMatch(voucher, ifMatch); err != nil {
+		return nil, nil, err
+	}
+
+	before := *voucher
+	if err := s.applyUpdate(voucher, req); err != nil {
+		return nil, nil, err
+	}
+
+	if reasons := s.approval.Check(&before, voucher); len(reasons) > 0 {
+		change, err := s.submitChange(models.ChangeActionUpdate, &voucher.ID, req, reasons, actor)
+		return nil, change, err
+	}
+
+	if err := s.saveUpdate(s.repo, voucher, req.Targets != nil, req.Schedules != nil, newAuditEntry(actor, models.AuditActionUpdate, &before, voucher)); err != nil {
+		return nil, nil, err
 	}
 
-	// Update fields if provided
+	return toVoucherResponse(voucher), nil, nil
+}
+
+// applyUpdate copies the fields set in the request onto the voucher and
+// normalizes the result, without saving it.
+func (s *voucherService) applyUpdate(voucher *models.Voucher, req dto.UpdateVoucherRequest) error {
 	if req.Code != "" {
-		existing, _ := s.repo.FindByCode(req.Code)
-		if existing != nil && existing.ID != voucher.ID {
-			return nil, errors.New("voucher code already exists")
-		}
 		voucher.Code = req.Code
 	}
 	if req.Name != "" {
@@ -135,6 +309,24 @@ func (s *voucherService) UpdateVoucher(id uint, req dto.UpdateVoucherRequest) (*
 	if req.Discount > 0 {
 		voucher.Discount = req.Discount
 	}
+	if req.DiscountType != "" {
+		voucher.DiscountType = req.DiscountType
+	}
+	if req.Currency != "" {
+		voucher.Currency = req.Currency
+	}
+	if req.MaxDiscount != nil {
+		voucher.MaxDiscount = *req.MaxDiscount
+	}
+	if req.MinOrderAmount != nil {
+		voucher.MinOrderAmount = *req.MinOrderAmount
+	}
+	if req.MinItemCount != nil {
+		voucher.MinItemCount = *req.MinItemCount
+	}
+	if req.MaxUsagePerCustomer != nil {
+		voucher.MaxUsagePerCustomer = *req.MaxUsagePerCustomer
+	}
 	if req.MaxUsage > 0 {
 		voucher.MaxUsage = req.MaxUsage
 	}
@@ -144,32 +336,148 @@ func (s *voucherService) UpdateVoucher(id uint, req dto.UpdateVoucherRequest) (*
 	if !req.ValidUntil.IsZero() {
 		voucher.ValidUntil = req.ValidUntil
 	}
-	if req.IsActive != nil {
-		voucher.IsActive = *req.IsActive
+	if req.StackingMode != "" {
+		voucher.StackingMode = req.StackingMode
+	}
+	if req.ExclusivityGroup != nil {
+		voucher.ExclusivityGroup = strings.TrimSpace(*req.ExclusivityGroup)
+	}
+	if req.Targets != nil {
+		voucher.Targets = toVoucherTargets(*req.Targets)
+	}
+	if req.Schedules != nil {
+		voucher.Schedules = toVoucherSchedules(*req.Schedules)
 	}
 
-	if err := s.repo.Update(voucher); err != nil {
-		return nil, err
+	if err := normalizeVoucher(voucher); err != nil {
+		return err
 	}
 
-	return s.toVoucherResponse(voucher), nil
+	campaignID := voucher.CampaignID
+	if req.CampaignID != nil {
+		campaignID = req.CampaignID
+	}
+	campaign, err := s.assignCampaign(voucher, campaignID)
+	if err != nil {
+		return err
+	}
+	voucher.Campaign = campaign
+	return nil
 }
 
-func (s *voucherService) DeleteVoucher(id uint) error {
-	_, err := s.repo.FindByID(id)
-	if err != nil {
+// saveUpdate writes an updated voucher and its audit entry, replacing targets
+// and schedules only when asked to, e.g. when the update request sent them.
+// The write fails with ErrVoucherModified if the voucher has changed since it
+// was read, so a concurrent edit or redemption is never overwritten.
+func (s *voucherService) saveUpdate(repo repository.VoucherRepository, voucher *models.Voucher, replaceTargets, replaceSchedules bool, entry models.AuditLog) error {
+	return repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := repo.WithTx(tx)
+		if err := txRepo.Update(voucher); err != nil {
+			if errors.Is(err, repository.ErrStaleVersion) {
+				return ErrVoucherModified
+			}
+			return codeConflict(err, voucher.Code)
+		}
+		if replaceTargets {
+			if err := txRepo.ReplaceTargets(voucher.ID, voucher.Targets); err != nil {
+				return err
+			}
+		}
+		if replaceSchedules {
+			if err := txRepo.ReplaceSchedules(voucher.ID, voucher.Schedules); err != nil {
+				return err
+			}
+		}
+		return s.auditRepo.WithTx(tx).Create([]models.AuditLog{entry})
+	})
+}
+
+func (s *voucherService) DeleteVoucher(id uint, ifMatch string, actor Actor) error {
+	return s.repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+
+		voucher, err := lockVoucher(txRepo, id)
+		if err != nil {
+			return err
+		}
+		if err := checkIfMatch(voucher, ifMatch); err != nil {
+			return err
+		}
+
+		if err := txRepo.Delete(id); err != nil {
+			return err
+		}
+		return s.auditRepo.WithTx(tx).Create([]models.AuditLog{newAuditEntry(actor, models.AuditActionDelete, voucher, nil)})
+	})
+}
+
+// lockVoucher locks the voucher, then loads it with targets and schedules for
+// the audit diff.
+func lockVoucher(repo repository.VoucherRepository, id uint) (*models.Voucher, error) {
+	if _, err := repo.FindByIDForUpdate(id); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("voucher not found")
+			return nil, ErrVoucherNotFound
 		}
-		return err
+		return nil, err
 	}
+	return repo.FindByID(id)
+}
 
-	return s.repo.Delete(id)
+// checkIfMatch fails with ErrVoucherModified unless the If-Match value names
+// the voucher's current ETag or is "*". An empty value skips the check; the
+// API requires the header before calling in.
+func checkIfMatch(voucher *models.Voucher, ifMatch string) error {
+	if ifMatch == "" {
+		return nil
+	}
+	if !utils.IfMatch(ifMatch, voucherETag(voucher)) {
+		return ErrVoucherModified
+	}
+	return nil
 }
 
-func (s *voucherService) ImportFromCSV(reader io.Reader) (*dto.CSVUploadResponse, error) {
+func voucherETag(voucher *models.Voucher) string {
+	return dto.VoucherETag(voucher.Version, string(voucher.State(time.Now())))
+}
+
+// TransitionVoucher applies a lifecycle transition under a row lock, so it is
+// checked against the voucher's state as concurrent redemptions leave it.
+func (s *voucherService) TransitionVoucher(id uint, transition models.VoucherTransition, actor Actor) (*dto.VoucherResponse, error) {
+	if !models.IsVoucherTransition(transition) {
+		return nil, fmt.Errorf("%w: unknown transition %q", ErrInvalidTransition, transition)
+	}
+
+	err := s.repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+
+		voucher, err := txRepo.FindByIDForUpdate(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVoucherNotFound
+			}
+			return err
+		}
+
+		now := time.Now()
+		before := *voucher
+		if !voucher.Transition(transition, now) {
+			return fmt.Errorf("%w: cannot %s a voucher that is %s", ErrInvalidTransition, transition, voucher.State(now))
+		}
+		if err := txRepo.UpdateStatus(voucher); err != nil {
+			return err
+		}
+		return s.auditRepo.WithTx(tx).Create([]models.AuditLog{newAuditEntry(actor, string(transition), &before, voucher)})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetVoucherByID(id)
+}
+
+func (s *voucherService) ImportFromCSV(reader io.Reader, actor Actor) (*dto.CSVUploadResponse, error) {
 	csvReader := csv.NewReader(reader)
-	
+
 	// Read header
 	header, err := csvReader.Read()
 	if err != nil {
@@ -182,7 +490,12 @@ func (s *voucherService) ImportFromCSV(reader io.Reader) (*dto.CSVUploadResponse
 		return nil, errors.New("invalid CSV header format")
 	}
 
+	// Optional columns may follow the required ones in any order
+	columns := csvColumnIndex(header)
+
 	var vouchers []models.Voucher
+	var rows []int
+	var rejected []string
 	rowNum := 1
 
 	// Read data rows
@@ -197,46 +510,77 @@ func (s *voucherService) ImportFromCSV(reader io.Reader) (*dto.CSVUploadResponse
 
 		rowNum++
 
-		voucher, err := s.parseCSVRow(record)
+		voucher, err := s.parseCSVRow(record, columns)
 		if err != nil {
 			continue
 		}
 
+		// Imports have no reviewer, so vouchers that need approval are left out
+		if reasons := s.approval.Check(nil, voucher); len(reasons) > 0 {
+			rejected = append(rejected, fmt.Sprintf("Row %d: %s needs approval, create it through POST /vouchers", rowNum, voucher.Code))
+			continue
+		}
+
 		vouchers = append(vouchers, *voucher)
+		rows = append(rows, rowNum)
 	}
 
-	successCount, errors := s.repo.BulkCreate(vouchers)
+	// Each row is created with its audit entry on its own, so one bad row
+	// does not undo the others
+	successCount := 0
+	errors := rejected
+	for i := range vouchers {
+		voucher := &vouchers[i]
+		if err := s.createVoucher(s.repo, voucher, newAuditEntry(actor, models.AuditActionImport, nil, voucher)); err != nil {
+			errors = append(errors, fmt.Sprintf("Row %d: %s", rows[i], err.Error()))
+			continue
+		}
+		successCount++
+	}
 
 	return &dto.CSVUploadResponse{
 		SuccessCount: successCount,
-		FailedCount:  len(vouchers) - successCount,
+		FailedCount:  len(vouchers) - successCount + len(rejected),
 		Errors:       errors,
 	}, nil
 }
 
-func (s *voucherService) ExportToCSV() ([][]string, error) {
-	vouchers, err := s.repo.ExportAll()
+// ExportToCSV exports the vouchers matching filter, the same ones the list
+// shows for it.
+func (s *voucherService) ExportToCSV(filter dto.VoucherFilter) ([][]string, error) {
+	vouchers, err := s.repo.Export(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create CSV data
+	// Create CSV data; is_active is derived, status is the stored status that import reads back
 	data := [][]string{
-		{"code", "name", "description", "discount", "max_usage", "used_count", "valid_from", "valid_until", "is_active", "created_at"},
+		{"code", "name", "description", "discount", "max_usage", "used_count", "valid_from", "valid_until", "is_active", "created_at", "discount_type", "max_discount", "min_order_amount", "min_item_count", "max_usage_per_customer", "stacking_mode", "exclusivity_group", "currency", "status"},
 	}
 
+	now := time.Now()
+
 	for _, voucher := range vouchers {
 		row := []string{
 			voucher.Code,
 			voucher.Name,
 			voucher.Description,
-			fmt.Sprintf("%.2f", voucher.Discount),
+			voucher.Discount.String(),
 			strconv.Itoa(voucher.MaxUsage),
 			strconv.Itoa(voucher.UsedCount),
 			voucher.ValidFrom.Format("2006-01-02 15:04:05"),
 			voucher.ValidUntil.Format("2006-01-02 15:04:05"),
-			strconv.FormatBool(voucher.IsActive),
+			strconv.FormatBool(voucher.State(now) == models.StateActive),
 			voucher.CreatedAt.Format("2006-01-02 15:04:05"),
+			voucher.DiscountType,
+			voucher.MaxDiscount.String(),
+			voucher.MinOrderAmount.String(),
+			strconv.Itoa(voucher.MinItemCount),
+			strconv.Itoa(voucher.MaxUsagePerCustomer),
+			voucher.StackingMode,
+			voucher.ExclusivityGroup,
+			voucher.Currency,
+			voucher.Status,
 		}
 		data = append(data, row)
 	}
@@ -244,12 +588,12 @@ func (s *voucherService) ExportToCSV() ([][]string, error) {
 	return data, nil
 }
 
-func (s *voucherService) parseCSVRow(record []string) (*models.Voucher, error) {
+func (s *voucherService) parseCSVRow(record []string, columns map[string]int) (*models.Voucher, error) {
 	if len(record) < 8 {
 		return nil, errors.New("invalid number of columns")
 	}
 
-	discount, err := strconv.ParseFloat(record[3], 64)
+	discount, err := models.ParseDecimal(record[3])
 	if err != nil {
 		return nil, fmt.Errorf("invalid discount value")
 	}
@@ -274,33 +618,176 @@ func (s *voucherService) parseCSVRow(record []string) (*models.Voucher, error) {
 		isActive, _ = strconv.ParseBool(record[7])
 	}
 
-	return &models.Voucher{
-		Code:        strings.TrimSpace(record[0]),
-		Name:        strings.TrimSpace(record[1]),
-		Description: strings.TrimSpace(record[2]),
-		Discount:    discount,
-		MaxUsage:    maxUsage,
-		ValidFrom:   validFrom,
-		ValidUntil:  validUntil,
-		IsActive:    isActive,
-	}, nil
+	// The optional status column wins over the older is_active flag
+	status := strings.ToLower(csvValue(record, columns, "status"))
+	if status == "" {
+		status = models.VoucherStatusPaused
+		if isActive {
+			status = models.VoucherStatusActive
+		}
+	}
+
+	voucher := &models.Voucher{
+		Code:             strings.TrimSpace(record[0]),
+		Name:             strings.TrimSpace(record[1]),
+		Description:      strings.TrimSpace(record[2]),
+		Discount:         discount,
+		DiscountType:     strings.ToLower(csvValue(record, columns, "discount_type")),
+		Currency:         csvValue(record, columns, "currency"),
+		MaxUsage:         maxUsage,
+		ValidFrom:        validFrom,
+		ValidUntil:       validUntil,
+		Status:           status,
+		StackingMode:     strings.ToLower(csvValue(record, columns, "stacking_mode")),
+		ExclusivityGroup: csvValue(record, columns, "exclusivity_group"),
+	}
+
+	if value := csvValue(record, columns, "max_discount"); value != "" {
+		maxDiscount, err := models.ParseMoney(value)
+		if err != nil || maxDiscount < 0 {
+			return nil, fmt.Errorf("invalid max_discount value")
+		}
+		voucher.MaxDiscount = maxDiscount
+	}
+
+	if value := csvValue(record, columns, "min_order_amount"); value != "" {
+		minOrderAmount, err := models.ParseMoney(value)
+		if err != nil || minOrderAmount < 0 {
+			return nil, fmt.Errorf("invalid min_order_amount value")
+		}
+		voucher.MinOrderAmount = minOrderAmount
+	}
+
+	if value := csvValue(record, columns, "min_item_count"); value != "" {
+		minItemCount, err := strconv.Atoi(value)
+		if err != nil || minItemCount < 0 {
+			return nil, fmt.Errorf("invalid min_item_count value")
+		}
+		voucher.MinItemCount = minItemCount
+	}
+
+	if value := csvValue(record, columns, "max_usage_per_customer"); value != "" {
+		maxUsagePerCustomer, err := strconv.Atoi(value)
+		if err != nil || maxUsagePerCustomer < 0 {
+			return nil, fmt.Errorf("invalid max_usage_per_customer value")
+		}
+		voucher.MaxUsagePerCustomer = maxUsagePerCustomer
+	}
+
+	if err := normalizeVoucher(voucher); err != nil {
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+// assignCampaign links the voucher to the campaign with the given ID and
+// returns it; nil or 0 leaves the voucher without a campaign. The voucher
+// must already have its final currency.
+func (s *voucherService) assignCampaign(voucher *models.Voucher, campaignID *uint) (*models.Campaign, error) {
+	if campaignID == nil || *campaignID == 0 {
+		voucher.CampaignID = nil
+		return nil, nil
+	}
+
+	campaign, err := s.campaignRepo.FindByID(*campaignID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, err
+	}
+	if campaign.Currency != voucher.Currency {
+		return nil, ErrCampaignCurrency
+	}
+
+	voucher.CampaignID = &campaign.ID
+	return campaign, nil
 }
 
-func (s *voucherService) toVoucherResponse(voucher *models.Voucher) *dto.VoucherResponse {
+func toVoucherResponse(voucher *models.Voucher) *dto.VoucherResponse {
+	state := voucher.State(time.Now())
 	return &dto.VoucherResponse{
-		ID:          voucher.ID,
-		Code:        voucher.Code,
-		Name:        voucher.Name,
-		Description: voucher.Description,
-		Discount:    voucher.Discount,
-		MaxUsage:    voucher.MaxUsage,
-		UsedCount:   voucher.UsedCount,
-		ValidFrom:   utils.NewReadableTime(voucher.ValidFrom),
-		ValidUntil:  utils.NewReadableTime(voucher.ValidUntil),
-		IsActive:    voucher.IsActive,
-		CreatedAt:   utils.NewReadableTime(voucher.CreatedAt),
-		UpdatedAt:   utils.NewReadableTime(voucher.UpdatedAt),
+		ID:                  voucher.ID,
+		Code:                voucher.Code,
+		Name:                voucher.Name,
+		Description:         voucher.Description,
+		Discount:            voucher.Discount,
+		DiscountType:        voucher.DiscountType,
+		Currency:            voucher.Currency,
+		MaxDiscount:         voucher.MaxDiscount,
+		MinOrderAmount:      voucher.MinOrderAmount,
+		MinItemCount:        voucher.MinItemCount,
+		MaxUsage:            voucher.MaxUsage,
+		MaxUsagePerCustomer: voucher.MaxUsagePerCustomer,
+		UsedCount:           voucher.UsedCount,
+		ReservedCount:       voucher.ReservedCount,
+		ValidFrom:           utils.NewReadableTime(voucher.ValidFrom),
+		ValidUntil:          utils.NewReadableTime(voucher.ValidUntil),
+		Status:              string(state),
+		IsActive:            state == models.StateActive,
+		StackingMode:        voucher.StackingMode,
+		ExclusivityGroup:    voucher.ExclusivityGroup,
+		CampaignID:          voucher.CampaignID,
+		Targets:             toVoucherTargetResponses(voucher.Targets),
+		Schedules:           toVoucherScheduleResponses(voucher.Schedules),
+		Version:             voucher.Version,
+		CreatedAt:           utils.NewReadableTime(voucher.CreatedAt),
+		UpdatedAt:           utils.NewReadableTime(voucher.UpdatedAt),
+	}
+}
+
+func toVoucherTargets(requests []dto.VoucherTargetRequest) []models.VoucherTarget {
+	targets := make([]models.VoucherTarget, 0, len(requests))
+	for _, req := range requests {
+		mode := req.Mode
+		if mode == "" {
+			mode = models.TargetModeInclude
+		}
+		targets = append(targets, models.VoucherTarget{
+			TargetType: req.Type,
+			Value:      strings.TrimSpace(req.Value),
+			Mode:       mode,
+		})
+	}
+	return targets
+}
+
+func toVoucherTargetResponses(targets []models.VoucherTarget) []dto.VoucherTargetResponse {
+	responses := make([]dto.VoucherTargetResponse, len(targets))
+	for i, target := range targets {
+		responses[i] = dto.VoucherTargetResponse{
+			Type:  target.TargetType,
+			Value: target.Value,
+			Mode:  target.Mode,
+		}
+	}
+	return responses
+}
+
+func toVoucherSchedules(requests []dto.VoucherScheduleRequest) []models.VoucherSchedule {
+	schedules := make([]models.VoucherSchedule, 0, len(requests))
+	for _, req := range requests {
+		schedules = append(schedules, models.VoucherSchedule{
+			Days:      strings.Join(req.Days, ","),
+			StartTime: req.StartTime,
+			EndTime:   req.EndTime,
+		})
+	}
+	return schedules
+}
+
+func toVoucherScheduleResponses(schedules []models.VoucherSchedule) []dto.VoucherScheduleResponse {
+	responses := make([]dto.VoucherScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		responses[i] = dto.VoucherScheduleResponse{
+			Days:      schedule.DayList(),
+			StartTime: schedule.StartTime,
+			EndTime:   schedule.EndTime,
+			Timezone:  models.ScheduleTimezone,
+		}
 	}
+	return responses
 }
 
 func validateCSVHeader(header, expected []string) bool {
@@ -314,3 +801,168 @@ func validateCSVHeader(header, expected []string) bool {
 	}
 	return true
 }
+
+func csvColumnIndex(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	return columns
+}
+
+func csvValue(record []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// normalizeVoucher fills in defaults and checks field combinations that
+// the request bindings cannot express.
+func normalizeVoucher(voucher *models.Voucher) error {
+	if err := normalizeCode(voucher); err != nil {
+		return err
+	}
+	if err := normalizeDiscount(voucher); err != nil {
+		return err
+	}
+	if err := normalizeStatus(voucher); err != nil {
+		return err
+	}
+	if err := normalizeCurrency(voucher); err != nil {
+		return err
+	}
+	if err := normalizeStacking(voucher); err != nil {
+		return err
+	}
+	if err := normalizeSchedules(voucher); err != nil {
+		return err
+	}
+	return checkVoucherRules(voucher)
+}
+
+// checkVoucherRules checks the rules that span several fields, on the voucher
+// as it will be saved, so a partial update cannot break them either.
+func checkVoucherRules(voucher *models.Voucher) error {
+	if !voucher.ValidUntil.After(voucher.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	if used := voucher.UsedCount + voucher.ReservedCount; voucher.MaxUsage < used {
+		return fmt.Errorf("max_usage cannot be lower than the %d uses already made or held", used)
+	}
+	return nil
+}
+
+// normalizeDiscount fills in the default discount type and checks the
+// discount fields against it. Caps only apply to percentage vouchers.
+func normalizeDiscount(voucher *models.Voucher) error {
+	switch voucher.DiscountType {
+	case "":
+		voucher.DiscountType = models.DiscountTypePercentage
+	case models.DiscountTypePercentage, models.DiscountTypeFixed:
+	default:
+		return fmt.Errorf("invalid discount type %q", voucher.DiscountType)
+	}
+
+	if voucher.IsFixedDiscount() {
+		// A discount of zero or less would leave the order at or above its total
+		if voucher.Discount <= 0 {
+			return ErrInvalidFixedDiscount
+		}
+		voucher.MaxDiscount = 0
+		return nil
+	}
+
+	// 100.00% in hundredths of a percent
+	if voucher.Discount < 0 || voucher.Discount > 100*100 {
+		return ErrInvalidPercentDiscount
+	}
+	return nil
+}
+
+// normalizeStatus defaults new vouchers to active and only accepts stored
+// statuses; derived states such as expired cannot be set directly.
+func normalizeStatus(voucher *models.Voucher) error {
+	switch voucher.Status {
+	case "":
+		voucher.Status = models.VoucherStatusActive
+	case models.VoucherStatusDraft, models.VoucherStatusActive, models.VoucherStatusPaused, models.VoucherStatusArchived:
+	default:
+		return fmt.Errorf("invalid status %q", voucher.Status)
+	}
+	return nil
+}
+
+// normalizeCode puts the voucher code in its stored form, which must still be
+// at least minCodeLength characters long.
+func normalizeCode(voucher *models.Voucher) error {
+	voucher.Code = models.NormalizeVoucherCode(voucher.Code)
+	if len(voucher.Code) < minCodeLength {
+		return fmt.Errorf("voucher code must have at least %d characters besides spaces and dashes", minCodeLength)
+	}
+	return nil
+}
+
+// normalizeCurrency uppercases the voucher currency, defaulting to IDR, and
+// checks that it is one Money can represent.
+func normalizeCurrency(voucher *models.Voucher) error {
+	voucher.Currency = strings.ToUpper(strings.TrimSpace(voucher.Currency))
+	if voucher.Currency == "" {
+		voucher.Currency = models.DefaultCurrency
+	}
+	if !models.IsSupportedCurrency(voucher.Currency) {
+		return fmt.Errorf("unsupported currency %q", voucher.Currency)
+	}
+	return nil
+}
+
+func normalizeStacking(voucher *models.Voucher) error {
+	switch voucher.StackingMode {
+	case "":
+		voucher.StackingMode = models.StackingModeExclusive
+	case models.StackingModeExclusive, models.StackingModeStackable:
+	default:
+		return fmt.Errorf("invalid stacking mode %q", voucher.StackingMode)
+	}
+
+	if len(voucher.ExclusivityGroup) > 50 {
+		return errors.New("exclusivity group must be at most 50 characters")
+	}
+	return nil
+}
+
+// normalizeSchedules lowercases and de-duplicates schedule days and checks
+// that each window has both ends and a non-zero length.
+func normalizeSchedules(voucher *models.Voucher) error {
+	for i := range voucher.Schedules {
+		schedule := &voucher.Schedules[i]
+
+		seen := make(map[string]bool)
+		var days []string
+		for _, day := range schedule.DayList() {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if _, ok := models.ScheduleDays[day]; !ok {
+				return fmt.Errorf("invalid schedule day %q", day)
+			}
+			if !seen[day] {
+				seen[day] = true
+				days = append(days, day)
+			}
+		}
+		schedule.Days = strings.Join(days, ",")
+
+		if schedule.StartTime == "" && schedule.EndTime == "" {
+			continue
+		}
+		start, okStart := models.ParseScheduleClock(schedule.StartTime)
+		end, okEnd := models.ParseScheduleClock(schedule.EndTime)
+		if !okStart || !okEnd {
+			return errors.New("schedule start_time and end_time must both be set in HH:MM format")
+		}
+		if start == end {
+			return errors.New("schedule start_time and end_time must differ")
+		}
+	}
+	return nil
+}
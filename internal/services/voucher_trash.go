@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/rifqi142/indico-be/internal/dto"
+	"github.com/rifqi142/indico-be/internal/models"
+	"github.com/rifqi142/indico-be/internal/repository"
+	"github.com/rifqi142/indico-be/internal/utils"
+	"gorm.io/gorm"
+)
+
+const trashPurgeBatchSize = 100
+
+var ErrTrashedVoucherNotFound = errors.New("voucher not found in trash")
+
+func (s *voucherService) GetTrash(query dto.TrashListQuery) (*dto.TrashListResponse, error) {
+	vouchers, total, err := s.repo.FindTrashed(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := 1
+	pageSize := 10
+	if query.Page > 0 {
+		page = query.Page
+	}
+	if query.PageSize > 0 {
+		pageSize = query.PageSize
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+
+	voucherResponses := make([]dto.TrashedVoucherResponse, len(vouchers))
+	for i, voucher := range vouchers {
+		voucherResponses[i] = dto.TrashedVoucherResponse{
+			VoucherResponse: *toVoucherResponse(&voucher),
+			DeletedAt:       utils.NewReadableTime(voucher.DeletedAt.Time),
+		}
+		if s.trashRetention > 0 {
+			voucherResponses[i].PurgeAt = utils.NewReadableTime(voucher.DeletedAt.Time.Add(s.trashRetention))
+		}
+	}
+
+	return &dto.TrashListResponse{
+		Data: voucherResponses,
+		Pagination: dto.PaginationMeta{
+			CurrentPage: page,
+			PageSize:    pageSize,
+			TotalPages:  totalPages,
+			TotalItems:  total,
+		},
+	}, nil
+}
+
+// RestoreVoucher brings a voucher back from the trash. If a voucher created
+// since the delete has taken its code, the restore fails with a conflict
+// unless the request gives a new code. A campaign deleted in the meantime is
+// detached.
+func (s *voucherService) RestoreVoucher(id uint, req dto.RestoreVoucherRequest, actor Actor) (*dto.VoucherResponse, error) {
+	err := s.repo.Transaction(func(tx *gorm.DB) error {
+		txRepo := s.repo.WithTx(tx)
+
+		voucher, err := lockTrashedVoucher(txRepo, id)
+		if err != nil {
+			return err
+		}
+		before := *voucher
+
+		if req.Code != "" {
+			voucher.Code = req.Code
+		}
+		if err := normalizeCode(voucher); err != nil {
+			return err
+		}
+		// Looked up first only to name the holder; the unique index decides
+		existing, err := txRepo.FindByCode(voucher.Code)
+		if err == nil {
+			return &VoucherCodeConflictError{Code: voucher.Code, VoucherID: existing.ID}
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if voucher.CampaignID != nil {
+			_, err := s.campaignRepo.WithTx(tx).FindByID(*voucher.CampaignID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				voucher.CampaignID = nil
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if err := txRepo.Restore(voucher); err != nil {
+			// A voucher created since the lookup may have taken the code
+			return codeConflict(err, voucher.Code)
+		}
+		return s.auditRepo.WithTx(tx).Create([]models.AuditLog{newAuditEntry(actor, models.AuditActionRestore, &before, voucher)})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetVoucherByID(id)
+}
+
+// PurgeVoucher permanently deletes a voucher that is in the trash.
+func (s *voucherService) PurgeVoucher(id uint, actor Actor) error {
+	return s.repo.Transaction(func(tx *gorm.DB) error {
+		voucher, err := lockTrashedVoucher(s.repo.WithTx(tx), id)
+		if err != nil {
+			return err
+		}
+		return s.purgeVoucher(tx, voucher, actor)
+	})
+}
+
+// PurgeExpiredTrash permanently deletes vouchers that have been in the trash
+// longer than the retention period, up to one batch per call. It does nothing
+// when retention is disabled.
+func (s *voucherService) PurgeExpiredTrash() (int, error) {
+	if s.trashRetention <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-s.trashRetention)
+	ids, err := s.repo.FindTrashedIDs(cutoff, trashPurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		err := s.repo.Transaction(func(tx *gorm.DB) error {
+			voucher, err := s.repo.WithTx(tx).FindTrashedByIDForUpdate(id)
+			if err != nil {
+				// Restored or purged since it was listed
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+
+			if err := s.purgeVoucher(tx, voucher, SystemActor); err != nil {
+				return err
+			}
+
+			purged++
+			return nil
+		})
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+func (s *voucherService) purgeVoucher(tx *gorm.DB, voucher *models.Voucher, actor Actor) error {
+	if err := s.repo.WithTx(tx).Purge(voucher.ID); err != nil {
+		return err
+	}
+	return s.auditRepo.WithTx(tx).Create([]models.AuditLog{newAuditEntry(actor, models.AuditActionPurge, voucher, nil)})
+}
+
+func lockTrashedVoucher(repo repository.VoucherRepository, id uint) (*models.Voucher, error) {
+	voucher, err := repo.FindTrashedByIDForUpdate(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTrashedVoucherNotFound
+		}
+		return nil, err
+	}
+	return voucher, nil
+}
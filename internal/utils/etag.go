@@ -0,0 +1,38 @@
+package utils
+
+import "strings"
+
+// IfMatch reports whether an If-Match header value is met by the current
+// etag: "*" matches any, otherwise one of the listed tags must be identical.
+// Weak tags never match, since If-Match uses the strong comparison.
+func IfMatch(header, etag string) bool {
+	for _, tag := range splitETags(header) {
+		if tag == "*" || (!strings.HasPrefix(tag, "W/") && tag == etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IfNoneMatch reports whether an If-None-Match header value names the current
+// etag, meaning the client's cached copy is still current. It uses the weak
+// comparison, which ignores the W/ prefix.
+func IfNoneMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range splitETags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func splitETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// RandomStringGenerator draws characters uniformly from a charset using
+// crypto/rand. Randomness is read in bulk, so generating many strings in a
+// row stays fast.
+type RandomStringGenerator struct {
+	charset []byte
+	reader  io.ByteReader
+	limit   int
+}
+
+func NewRandomStringGenerator(charset string) (*RandomStringGenerator, error) {
+	if len(charset) < 2 || len(charset) > 256 {
+		return nil, errors.New("charset must have between 2 and 256 characters")
+	}
+
+	return &RandomStringGenerator{
+		charset: []byte(charset),
+		reader:  bufio.NewReaderSize(rand.Reader, 64*1024),
+		// Bytes at or above limit are skipped so every character is equally likely
+		limit: 256 - 256%len(charset),
+	}, nil
+}
+
+func (g *RandomStringGenerator) Next(length int) (string, error) {
+	out := make([]byte, length)
+	for i := 0; i < length; {
+		b, err := g.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if int(b) >= g.limit {
+			continue
+		}
+		out[i] = g.charset[int(b)%len(g.charset)]
+		i++
+	}
+	return string(out), nil
+}
@@ -29,6 +29,14 @@ func CreatedResponse(c *gin.Context, message string, data interface{}) {
 	})
 }
 
+func AcceptedResponse(c *gin.Context, message string, data interface{}) {
+	c.JSON(http.StatusAccepted, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
 func ErrorResponse(c *gin.Context, statusCode int, message string, err interface{}) {
 	c.JSON(statusCode, Response{
 		Success: false,
@@ -45,10 +53,30 @@ func UnauthorizedResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusUnauthorized, message, nil)
 }
 
+func ForbiddenResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusForbidden, message, nil)
+}
+
 func NotFoundResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusNotFound, message, nil)
 }
 
+func ConflictResponse(c *gin.Context, message string, err interface{}) {
+	ErrorResponse(c, http.StatusConflict, message, err)
+}
+
+func PreconditionFailedResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusPreconditionFailed, message, nil)
+}
+
+func PreconditionRequiredResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusPreconditionRequired, message, nil)
+}
+
+func UnsupportedMediaTypeResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusUnsupportedMediaType, message, nil)
+}
+
 func InternalServerErrorResponse(c *gin.Context, message string, err interface{}) {
 	ErrorResponse(c, http.StatusInternalServerError, message, err)
 }
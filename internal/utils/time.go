@@ -119,3 +119,22 @@ func FormatToIndonesianWithTime(t time.Time) string {
 func NewReadableTime(t time.Time) ReadableTime {
 	return ReadableTime{Time: t}
 }
+
+// ReadableDateTime is like ReadableTime but keeps the time of day, for
+// timestamps where minutes matter.
+type ReadableDateTime struct {
+	time.Time
+}
+
+func (rt ReadableDateTime) MarshalJSON() ([]byte, error) {
+	if rt.Time.IsZero() {
+		return []byte("null"), nil
+	}
+
+	formatted := FormatToIndonesianWithTime(rt.Time)
+	return []byte(fmt.Sprintf(`"%s"`, formatted)), nil
+}
+
+func NewReadableDateTime(t time.Time) ReadableDateTime {
+	return ReadableDateTime{Time: t}
+}